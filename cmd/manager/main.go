@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
 	"github.com/ouzi-dev/node-tagger/pkg/env"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -51,7 +54,136 @@ func init() {
 		"tags",
 		"t",
 		map[string]string{},
-		"Tags to add to the aws instances on which the cluster nodes run on")
+		"Tags to add to the aws instances on which the cluster nodes run on. "+
+			"They are applied before the tags of any NodeTagPolicy")
+
+	pflag.StringSliceVar(
+		&flags.Providers,
+		"providers",
+		[]string{"aws"},
+		"Cloud providers whose nodes are tagged, matched against the scheme of the node ProviderIDs. "+
+			"Nodes of any other provider are skipped")
+
+	pflag.StringVar(
+		&flags.ConflictPolicy,
+		"conflict-policy",
+		string(tagging.ConflictPolicyOverwrite),
+		"What to do when an instance already has a tag with a different value that node-tagger did not apply: "+
+			"overwrite, keep-existing or fail. NodeTagPolicies can set their own")
+
+	pflag.StringVar(
+		&flags.AssumeRoleConfig,
+		"assume-role-config",
+		"",
+		"YAML file mapping node selectors or AWS account IDs to the IAM roles assumed to tag the nodes of other "+
+			"accounts")
+
+	pflag.DurationVar(
+		&flags.AWSBatchWindow,
+		"aws-batch-window",
+		100*time.Millisecond,
+		"How long to gather the EC2 instance lookups and tag writes of concurrently reconciled nodes into a single "+
+			"API call. 0 disables batching, and so does a --max-concurrent-reconciles of 1")
+
+	pflag.DurationVar(
+		&flags.AWSTagCacheTTL,
+		"aws-tag-cache-ttl",
+		10*time.Minute,
+		"How long the tags of an EC2 instance are trusted without describing the instance again. "+
+			"0 disables the cache, and so does tagging the volumes or network interfaces of the instances")
+
+	pflag.DurationVar(
+		&flags.ResyncPeriod,
+		"resync-period",
+		time.Hour,
+		"How often every node is reconciled again with the tags read from its cloud provider, restoring the ones "+
+			"changed outside node-tagger. 0 disables it")
+
+	pflag.BoolVar(
+		&flags.TagRootVolumes,
+		"tag-root-volumes",
+		false,
+		"Also apply the tags to the EBS root volumes of the instances")
+
+	pflag.BoolVar(
+		&flags.TagDataVolumes,
+		"tag-data-volumes",
+		false,
+		"Also apply the tags to the EBS volumes attached to the instances other than the root ones")
+
+	pflag.BoolVar(
+		&flags.TagNetworkInterfaces,
+		"tag-network-interfaces",
+		false,
+		"Also apply the tags to the network interfaces attached to the instances")
+
+	pflag.BoolVar(
+		&flags.TagPersistentVolumes,
+		"tag-persistent-volumes",
+		false,
+		"Tag the EBS volumes behind PersistentVolumes with tags derived from their claims")
+
+	pflag.StringToStringVar(
+		&flags.VolumeTags,
+		"volume-tags",
+		map[string]string{},
+		"Tags to add to the EBS volumes behind PersistentVolumes, rendered against their claims. "+
+			"They are applied before the tags annotations of the namespace and the claim")
+
+	pflag.BoolVar(
+		&flags.TagSnapshots,
+		"tag-snapshots",
+		false,
+		"Tag the EBS snapshots behind VolumeSnapshotContents with the tags of the claims they were taken from")
+
+	pflag.BoolVar(
+		&flags.TagLoadBalancers,
+		"tag-load-balancers",
+		false,
+		"Tag the load balancers of Services of type LoadBalancer with tags derived from the Services")
+
+	pflag.StringToStringVar(
+		&flags.LoadBalancerTags,
+		"load-balancer-tags",
+		map[string]string{},
+		"Tags to add to the load balancers of Services, rendered against the Services. "+
+			"They are applied before the tags annotations of the namespace and the Service")
+
+	pflag.IntVar(
+		&flags.MaxConcurrentReconciles,
+		"max-concurrent-reconciles",
+		1,
+		"Number of resources every controller reconciles at once")
+
+	pflag.Float64Var(
+		&flags.AWSDescribeRate,
+		"aws-describe-rate",
+		20,
+		"Calls per second node-tagger makes to the AWS APIs to read resources")
+
+	pflag.IntVar(
+		&flags.AWSDescribeBurst,
+		"aws-describe-burst",
+		40,
+		"Calls node-tagger makes at once to the AWS APIs to read resources")
+
+	pflag.Float64Var(
+		&flags.AWSMutateRate,
+		"aws-mutate-rate",
+		5,
+		"Calls per second node-tagger makes to the AWS APIs to change resources, such as tag writes")
+
+	pflag.IntVar(
+		&flags.AWSMutateBurst,
+		"aws-mutate-burst",
+		10,
+		"Calls node-tagger makes at once to the AWS APIs to change resources, such as tag writes")
+
+	pflag.IntVar(
+		&flags.AWSMaxRetries,
+		"aws-max-retries",
+		5,
+		"Number of times a failed or throttled call to the AWS APIs is retried, with a jittered exponential backoff")
 
 	pflag.StringVarP(
 		&flags.LeaderElectionNamespace,
@@ -99,9 +231,47 @@ func main() {
 
 	printVersion()
 
-	// Validate that the list of tags is not empty
-	if len(flags.InstanceTags) == 0 {
-		log.Error(errors.New("at least one tag must be provided"), "")
+	if _, err := tagging.ParseConflictPolicy(flags.ConflictPolicy); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	// Reject invalid tags up front instead of failing every reconcile
+	if err := tagging.ValidateTemplates(flags.InstanceTags); err != nil {
+		log.Error(err, "Invalid --tags")
+		os.Exit(1)
+	}
+
+	if err := tagging.ValidateTemplates(flags.VolumeTags); err != nil {
+		log.Error(err, "Invalid --volume-tags")
+		os.Exit(1)
+	}
+
+	if err := tagging.ValidateTemplates(flags.LoadBalancerTags); err != nil {
+		log.Error(err, "Invalid --load-balancer-tags")
+		os.Exit(1)
+	}
+
+	if flags.ResyncPeriod < 0 {
+		log.Error(nil, "--resync-period must not be negative")
+		os.Exit(1)
+	}
+
+	if flags.MaxConcurrentReconciles < 1 {
+		log.Error(nil, "--max-concurrent-reconciles must be at least 1")
+		os.Exit(1)
+	}
+
+	// Limit the calls of every AWS client, which are only created once the controllers are added
+	err := aws.ConfigureAPILimits(aws.APILimits{
+		DescribeRate:  flags.AWSDescribeRate,
+		DescribeBurst: flags.AWSDescribeBurst,
+		MutateRate:    flags.AWSMutateRate,
+		MutateBurst:   flags.AWSMutateBurst,
+		MaxRetries:    flags.AWSMaxRetries,
+	})
+	if err != nil {
+		log.Error(err, "Invalid AWS API limits")
 		os.Exit(1)
 	}
 
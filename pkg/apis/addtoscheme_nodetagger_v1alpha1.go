@@ -0,0 +1,10 @@
+package apis
+
+import (
+	"github.com/ouzi-dev/node-tagger/pkg/apis/nodetagger/v1alpha1"
+)
+
+func init() {
+	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
+	AddToSchemes = append(AddToSchemes, v1alpha1.SchemeBuilder.AddToScheme)
+}
@@ -0,0 +1,6 @@
+// Package nodetagger contains nodetagger API versions.
+//
+// This file ensures Go source parsers acknowledge the nodetagger package
+// and any child packages. It can be removed if any other Go source files are
+// added to this package.
+package nodetagger
@@ -0,0 +1,4 @@
+// Package v1alpha1 contains API Schema definitions for the nodetagger v1alpha1 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=nodetagger.ouzi.dev
+package v1alpha1
@@ -0,0 +1,44 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeTagPolicySpec defines the desired state of NodeTagPolicy
+type NodeTagPolicySpec struct {
+	// Tags to add to the instances on which the cluster nodes run on
+	Tags map[string]string `json:"tags"`
+	// NodeSelector restricts the policy to the nodes whose labels match it. An empty or missing selector matches
+	// every node
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// NodeNameRegex restricts the policy to the nodes whose name matches the regular expression
+	NodeNameRegex string `json:"nodeNameRegex,omitempty"`
+	// ConflictPolicy decides what happens when an instance already has one of the tags with a different value that
+	// node-tagger did not apply. Defaults to the conflict policy from the command line
+	// +kubebuilder:validation:Enum=overwrite;keep-existing;fail
+	ConflictPolicy string `json:"conflictPolicy,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeTagPolicy is the Schema for the nodetagpolicies API
+// +kubebuilder:resource:path=nodetagpolicies,scope=Cluster
+type NodeTagPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NodeTagPolicySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeTagPolicyList contains a list of NodeTagPolicy
+type NodeTagPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeTagPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeTagPolicy{}, &NodeTagPolicyList{})
+}
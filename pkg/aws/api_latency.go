@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+)
+
+// apiLatency observes how long every attempt of the calls to the AWS APIs takes, from sending the request to reading
+// the response, so the waits for the rate limiter and between retries are left out
+type apiLatency struct {
+	// started holds the start time of the attempts in flight, keyed by their request
+	started sync.Map
+}
+
+// sharedAPILatency observes the calls of every session created by GetAwsSessionFromEnv
+var sharedAPILatency = &apiLatency{}
+
+func (l *apiLatency) apply(handlers *request.Handlers) {
+	handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "nodetagger.LatencyStart",
+		Fn:   l.start,
+	})
+
+	// The attempt completes even when sending the request fails
+	handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{
+		Name: "nodetagger.LatencyObserve",
+		Fn:   l.observe,
+	})
+}
+
+func (l *apiLatency) start(r *request.Request) {
+	l.started.Store(r, time.Now())
+}
+
+func (l *apiLatency) observe(r *request.Request) {
+	start, found := l.started.Load(r)
+	if !found {
+		return
+	}
+
+	l.started.Delete(r)
+
+	metrics.AWSAPILatency.WithLabelValues(r.ClientInfo.ServiceName, r.Operation.Name).
+		Observe(time.Since(start.(time.Time)).Seconds())
+}
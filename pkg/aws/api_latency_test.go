@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// observedAttempts returns the number of attempts of the EC2 operation whose latency was observed
+func observedAttempts(t *testing.T, operation string) uint64 {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.AWSAPILatency)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["service"] == "ec2" && labels["operation"] == operation {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestAPILatency_ObservesEveryAttempt(t *testing.T) {
+	limiter := newAPILimiter(APILimits{DescribeRate: 100, DescribeBurst: 1, MutateRate: 100, MutateBurst: 1,
+		MaxRetries: 2})
+	limiter.minThrottleDelay = time.Millisecond
+	limiter.maxThrottleDelay = 10 * time.Millisecond
+
+	var calls int32
+
+	ec2Client := newLimitedEC2Client(t, limiter, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(throttlingResponse))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(createTagsResponse))
+	})
+
+	latency := &apiLatency{}
+	latency.apply(&ec2Client.Handlers)
+
+	attemptsBefore := observedAttempts(t, "CreateTags")
+
+	_, err := ec2Client.CreateTags(&ec2.CreateTagsInput{
+		Resources: []*string{aws.String(instanceID)},
+		Tags:      convertDesiredTagsToAwsTags(inputTags),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, attemptsBefore+2, observedAttempts(t, "CreateTags"))
+
+	inFlight := 0
+	latency.started.Range(func(key, value interface{}) bool {
+		inFlight++
+		return true
+	})
+	assert.Equal(t, 0, inFlight)
+}
+
+func TestAPILatency_IgnoresAttemptsNeverSent(t *testing.T) {
+	latency := &apiLatency{}
+	attemptsBefore := observedAttempts(t, "DescribeInstances")
+
+	latency.observe(&request.Request{Operation: &request.Operation{Name: "DescribeInstances"}})
+
+	assert.Equal(t, attemptsBefore, observedAttempts(t, "DescribeInstances"))
+}
@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// minThrottleDelay and maxThrottleDelay bound the jittered exponential backoff of throttled calls
+	minThrottleDelay = time.Second
+	maxThrottleDelay = 30 * time.Second
+
+	describeBudget = "describe"
+	mutateBudget   = "mutate"
+)
+
+// APILimits sets the rates at which node-tagger calls the AWS APIs, with separate token buckets for the calls that
+// read resources and the ones that change them, and how many times throttled calls are retried
+type APILimits struct {
+	// DescribeRate is the number of describe calls per second, and DescribeBurst the number of calls made at once
+	DescribeRate  float64
+	DescribeBurst int
+	// MutateRate is the number of calls per second changing resources, such as tag writes, and MutateBurst the number
+	// of calls made at once
+	MutateRate  float64
+	MutateBurst int
+	// MaxRetries is the number of times a failed or throttled call is retried
+	MaxRetries int
+}
+
+// Validate checks the limits can be reached
+func (l APILimits) Validate() error {
+	if l.DescribeRate <= 0 || l.MutateRate <= 0 {
+		return errors.New("the AWS API rates must be positive")
+	}
+
+	if l.DescribeBurst < 1 || l.MutateBurst < 1 {
+		return errors.New("the AWS API bursts must be at least 1")
+	}
+
+	if l.MaxRetries < 0 {
+		return errors.New("the number of AWS API retries can not be negative")
+	}
+
+	return nil
+}
+
+// apiLimiter limits the rate of the calls of every AWS client created from the sessions of node-tagger, so the
+// reconciles of every controller share the same budgets
+type apiLimiter struct {
+	limits   APILimits
+	describe *rate.Limiter
+	mutate   *rate.Limiter
+
+	minThrottleDelay time.Duration
+	maxThrottleDelay time.Duration
+}
+
+// sharedAPILimiter is set by ConfigureAPILimits. The sessions created before are not limited
+var sharedAPILimiter *apiLimiter
+
+// ConfigureAPILimits limits the calls of the sessions created from now on by GetAwsSessionFromEnv
+func ConfigureAPILimits(limits APILimits) error {
+	err := limits.Validate()
+	if err != nil {
+		return err
+	}
+
+	sharedAPILimiter = newAPILimiter(limits)
+
+	return nil
+}
+
+func newAPILimiter(limits APILimits) *apiLimiter {
+	return &apiLimiter{
+		limits:   limits,
+		describe: rate.NewLimiter(rate.Limit(limits.DescribeRate), limits.DescribeBurst),
+		mutate:   rate.NewLimiter(rate.Limit(limits.MutateRate), limits.MutateBurst),
+
+		minThrottleDelay: minThrottleDelay,
+		maxThrottleDelay: maxThrottleDelay,
+	}
+}
+
+// apply waits for a token of the budget of the call before every attempt, including the retries, and retries the
+// throttled calls with a jittered exponential backoff
+func (l *apiLimiter) apply(handlers *request.Handlers) {
+	handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: "nodetagger.RateLimiter",
+		Fn:   l.wait,
+	})
+
+	handlers.Retry.PushBackNamed(request.NamedHandler{
+		Name: "nodetagger.ThrottleCounter",
+		Fn:   countThrottle,
+	})
+}
+
+func (l *apiLimiter) retryer() request.Retryer {
+	return &throttleRetryer{
+		DefaultRetryer: client.DefaultRetryer{
+			NumMaxRetries:    l.limits.MaxRetries,
+			MinThrottleDelay: l.minThrottleDelay,
+			MaxThrottleDelay: l.maxThrottleDelay,
+		},
+	}
+}
+
+func (l *apiLimiter) wait(r *request.Request) {
+	budget, limiter := mutateBudget, l.mutate
+	if isDescribeOperation(r.Operation.Name) {
+		budget, limiter = describeBudget, l.describe
+	}
+
+	start := time.Now()
+
+	err := limiter.Wait(r.Context())
+
+	metrics.AWSRateLimiterWait.WithLabelValues(budget).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		r.Error = errors.Wrapf(err, "rate limiting %s %s", r.ClientInfo.ServiceName, r.Operation.Name)
+	}
+}
+
+// isDescribeOperation tells whether the operation only reads resources
+func isDescribeOperation(operation string) bool {
+	for _, prefix := range []string{"Describe", "Get", "List"} {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func countThrottle(r *request.Request) {
+	if r.IsErrorThrottle() {
+		metrics.AWSThrottledCalls.WithLabelValues(r.ClientInfo.ServiceName, r.Operation.Name).Inc()
+	}
+}
+
+// throttleRetryer retries the calls like the default retryer of the SDK, whose delay grows exponentially with jitter
+// and starts from a longer delay for throttled calls, and records how long throttled calls wait
+type throttleRetryer struct {
+	client.DefaultRetryer
+}
+
+func (t *throttleRetryer) RetryRules(r *request.Request) time.Duration {
+	delay := t.DefaultRetryer.RetryRules(r)
+
+	if r.IsErrorThrottle() {
+		metrics.AWSThrottleBackoff.WithLabelValues(r.ClientInfo.ServiceName).Observe(delay.Seconds())
+	}
+
+	return delay
+}
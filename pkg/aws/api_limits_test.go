@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	throttlingResponse = `<Response><Errors><Error><Code>RequestLimitExceeded</Code>` +
+		`<Message>Request limit exceeded.</Message></Error></Errors><RequestID>request-id</RequestID></Response>`
+	createTagsResponse = `<CreateTagsResponse><requestId>request-id</requestId><return>true</return>` +
+		`</CreateTagsResponse>`
+)
+
+func TestAPILimits_Validate(t *testing.T) {
+	valid := APILimits{DescribeRate: 20, DescribeBurst: 40, MutateRate: 5, MutateBurst: 10, MaxRetries: 5}
+
+	zeroRate := valid
+	zeroRate.MutateRate = 0
+
+	zeroBurst := valid
+	zeroBurst.DescribeBurst = 0
+
+	negativeRetries := valid
+	negativeRetries.MaxRetries = -1
+
+	tests := []struct {
+		testName      string
+		limits        APILimits
+		expectedError string
+	}{
+		{testName: "valid limits", limits: valid},
+		{testName: "zero rate", limits: zeroRate, expectedError: "the AWS API rates must be positive"},
+		{testName: "zero burst", limits: zeroBurst, expectedError: "the AWS API bursts must be at least 1"},
+		{
+			testName:      "negative retries",
+			limits:        negativeRetries,
+			expectedError: "the number of AWS API retries can not be negative",
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			err := testData.limits.Validate()
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestIsDescribeOperation(t *testing.T) {
+	assert.True(t, isDescribeOperation("DescribeInstances"))
+	assert.True(t, isDescribeOperation("GetCallerIdentity"))
+	assert.True(t, isDescribeOperation("ListTagsForResource"))
+	assert.False(t, isDescribeOperation("CreateTags"))
+	assert.False(t, isDescribeOperation("AssumeRole"))
+}
+
+// newLimitedEC2Client creates an EC2 client whose calls are limited by the limiter and sent to the handler
+func newLimitedEC2Client(t *testing.T, limiter *apiLimiter, handler http.HandlerFunc) *ec2.EC2 {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := aws.NewConfig().
+		WithRegion("eu-west-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", ""))
+
+	sess, err := session.NewSession(request.WithRetryer(config, limiter.retryer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limiter.apply(&sess.Handlers)
+
+	return ec2.New(sess)
+}
+
+func TestAPILimiter_RetriesThrottledCalls(t *testing.T) {
+	limiter := newAPILimiter(APILimits{DescribeRate: 100, DescribeBurst: 1, MutateRate: 100, MutateBurst: 1,
+		MaxRetries: 2})
+	limiter.minThrottleDelay = time.Millisecond
+	limiter.maxThrottleDelay = 10 * time.Millisecond
+
+	var calls int32
+
+	ec2Client := newLimitedEC2Client(t, limiter, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(throttlingResponse))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(createTagsResponse))
+	})
+
+	throttles := metrics.AWSThrottledCalls.WithLabelValues("ec2", "CreateTags")
+	throttlesBefore := testutil.ToFloat64(throttles)
+
+	_, err := ec2Client.CreateTags(&ec2.CreateTagsInput{
+		Resources: []*string{aws.String(instanceID)},
+		Tags:      convertDesiredTagsToAwsTags(inputTags),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, throttlesBefore+1, testutil.ToFloat64(throttles))
+}
+
+func TestAPILimiter_ReturnsThrottlingError_If_RetriesAreExhausted(t *testing.T) {
+	limiter := newAPILimiter(APILimits{DescribeRate: 100, DescribeBurst: 1, MutateRate: 100, MutateBurst: 1,
+		MaxRetries: 1})
+	limiter.minThrottleDelay = time.Millisecond
+	limiter.maxThrottleDelay = 10 * time.Millisecond
+
+	var calls int32
+
+	ec2Client := newLimitedEC2Client(t, limiter, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(throttlingResponse))
+	})
+
+	_, err := ec2Client.CreateTags(&ec2.CreateTagsInput{
+		Resources: []*string{aws.String(instanceID)},
+		Tags:      convertDesiredTagsToAwsTags(inputTags),
+	})
+
+	awsErr, ok := err.(awserr.Error)
+	assert.True(t, ok)
+	assert.Equal(t, "RequestLimitExceeded", awsErr.Code())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestAPILimiter_SpacesCallsOfTheSameBudget(t *testing.T) {
+	limiter := newAPILimiter(APILimits{DescribeRate: 1000, DescribeBurst: 100, MutateRate: 20, MutateBurst: 1,
+		MaxRetries: 0})
+
+	ec2Client := newLimitedEC2Client(t, limiter, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(createTagsResponse))
+	})
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := ec2Client.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(instanceID)},
+			Tags:      convertDesiredTagsToAwsTags(inputTags),
+		})
+		assert.NoError(t, err)
+	}
+
+	// The burst of one lets the first call through and the other two wait 50ms each for a token
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+}
@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"io/ioutil"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// roleARNRegex matches the ARN of an IAM role and captures the ID of its account
+var roleARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:iam::([0-9]{12}):role/.+$`)
+
+// AssumeRoleMapping selects the IAM role node-tagger assumes to tag the instances of the nodes in another account
+type AssumeRoleMapping struct {
+	// AccountID is the account of the role. Nodes whose account ID label matches it are tagged with the role.
+	// Defaults to the account of the role ARN
+	AccountID string `json:"accountID,omitempty"`
+	// RoleARN is the ARN of the role to assume
+	RoleARN string `json:"roleARN"`
+	// ExternalID is passed when assuming the role, if the trust policy of the role requires it
+	ExternalID string `json:"externalID,omitempty"`
+	// NodeSelector also tags the nodes whose labels match it with the role
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// LoadAssumeRoleMappings reads the YAML list of mappings from the file
+func LoadAssumeRoleMappings(path string) ([]AssumeRoleMapping, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := []AssumeRoleMapping{}
+
+	err = yaml.UnmarshalStrict(content, &mappings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid assume role mappings in %s", path)
+	}
+
+	for i := range mappings {
+		err = mappings[i].complete()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid assume role mapping %d in %s", i, path)
+		}
+	}
+
+	return mappings, nil
+}
+
+// complete validates the mapping and defaults its account ID to the account of the role
+func (m *AssumeRoleMapping) complete() error {
+	match := roleARNRegex.FindStringSubmatch(m.RoleARN)
+	if match == nil {
+		return errors.Errorf("%q is not the ARN of an IAM role", m.RoleARN)
+	}
+
+	if m.AccountID == "" {
+		m.AccountID = match[1]
+	}
+
+	if m.AccountID != match[1] {
+		return errors.Errorf("role %s is not in account %s", m.RoleARN, m.AccountID)
+	}
+
+	if m.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(m.NodeSelector); err != nil {
+			return errors.Wrap(err, "invalid node selector")
+		}
+	}
+
+	return nil
+}
+
+// Account tags the nodes matching the mapping with the credentials of its role
+type Account struct {
+	Mapping     AssumeRoleMapping
+	Credentials *credentials.Credentials
+	NodeTagger  provider.NodeTagger
+}
+
+type account struct {
+	Account
+	selector labels.Selector
+}
+
+// accountNodeTagger tags the nodes of other accounts with the role mapped to them, and every other node with the
+// default NodeTagger using the identity of node-tagger
+type accountNodeTagger struct {
+	defaultNodeTagger provider.NodeTagger
+	accounts          []account
+}
+
+// NewAccountNodeTagger routes every node to the first account whose mapping matches it, either by its node selector or
+// by the account ID label of the node, and to the default NodeTagger when none does
+func NewAccountNodeTagger(defaultNodeTagger provider.NodeTagger, accounts []Account) (provider.NodeTagger, error) {
+	tagger := &accountNodeTagger{
+		defaultNodeTagger: defaultNodeTagger,
+	}
+
+	for _, acc := range accounts {
+		selector := labels.Nothing()
+
+		if acc.Mapping.NodeSelector != nil {
+			var err error
+
+			selector, err = metav1.LabelSelectorAsSelector(acc.Mapping.NodeSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid node selector for role %s", acc.Mapping.RoleARN)
+			}
+		}
+
+		tagger.accounts = append(tagger.accounts, account{Account: acc, selector: selector})
+	}
+
+	return tagger, nil
+}
+
+func (a *accountNodeTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	acc := a.nodeAccount(node)
+	if acc == nil {
+		return a.defaultNodeTagger.EnsureInstanceNodeHasTags(node, tags, conflictPolicies)
+	}
+
+	// The credentials are cached until they are about to expire, so this only calls STS to refresh them
+	_, err := acc.Credentials.Get()
+	if err != nil {
+		log.Error(err, "Failed to assume role.", "Node.Name", node.Name, "Account.ID", acc.Mapping.AccountID,
+			"Role.ARN", acc.Mapping.RoleARN)
+		metrics.CredentialErrors.WithLabelValues(acc.Mapping.AccountID).Inc()
+
+		return provider.TagResult{}, wrapPermissionDenied(errors.Wrapf(err, "failed to assume role %s for account %s",
+			acc.Mapping.RoleARN, acc.Mapping.AccountID))
+	}
+
+	return acc.NodeTagger.EnsureInstanceNodeHasTags(node, tags, conflictPolicies)
+}
+
+// Resync makes the default NodeTagger and the ones of every account read the tags of their instances again
+func (a *accountNodeTagger) Resync() {
+	nodeTaggers := []provider.NodeTagger{a.defaultNodeTagger}
+	for _, acc := range a.accounts {
+		nodeTaggers = append(nodeTaggers, acc.NodeTagger)
+	}
+
+	for _, nodeTagger := range nodeTaggers {
+		if resyncer, ok := nodeTagger.(provider.Resyncer); ok {
+			resyncer.Resync()
+		}
+	}
+}
+
+func (a *accountNodeTagger) nodeAccount(node *corev1.Node) *account {
+	accountID := node.Labels[constants.AccountIDLabel]
+
+	for i := range a.accounts {
+		acc := &a.accounts[i]
+
+		if acc.selector.Matches(labels.Set(node.Labels)) || (accountID != "" && accountID == acc.Mapping.AccountID) {
+			return acc
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	otherAccountID = "123456789012"
+	otherRoleARN   = "arn:aws:iam::123456789012:role/node-tagger"
+)
+
+func TestLoadAssumeRoleMappings(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		expectedMappings []AssumeRoleMapping
+		expectedError    string
+	}{
+		{
+			name: "valid mappings",
+			content: `
+- roleARN: arn:aws:iam::123456789012:role/node-tagger
+  externalID: secret
+- accountID: "210987654321"
+  roleARN: arn:aws-us-gov:iam::210987654321:role/node-tagger
+  nodeSelector:
+    matchLabels:
+      team: machine-learning
+`,
+			expectedMappings: []AssumeRoleMapping{
+				{
+					AccountID:  otherAccountID,
+					RoleARN:    otherRoleARN,
+					ExternalID: "secret",
+				},
+				{
+					AccountID: "210987654321",
+					RoleARN:   "arn:aws-us-gov:iam::210987654321:role/node-tagger",
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"team": "machine-learning"},
+					},
+				},
+			},
+		},
+		{
+			name:          "invalid role ARN",
+			content:       `[{roleARN: node-tagger}]`,
+			expectedError: `invalid assume role mapping 0 in mappings.yaml: "node-tagger" is not the ARN of an IAM role`,
+		},
+		{
+			name:    "role of another account",
+			content: `[{accountID: "210987654321", roleARN: "arn:aws:iam::123456789012:role/node-tagger"}]`,
+			expectedError: "invalid assume role mapping 0 in mappings.yaml: " +
+				"role arn:aws:iam::123456789012:role/node-tagger is not in account 210987654321",
+		},
+		{
+			name: "invalid node selector",
+			content: `[{roleARN: "arn:aws:iam::123456789012:role/node-tagger", ` +
+				`nodeSelector: {matchExpressions: [{key: team, operator: Equals}]}}]`,
+			expectedError: `invalid assume role mapping 0 in mappings.yaml: invalid node selector: ` +
+				`"Equals" is not a valid pod selector operator`,
+		},
+		{
+			name:    "unknown field",
+			content: `[{role: "arn:aws:iam::123456789012:role/node-tagger"}]`,
+			expectedError: `invalid assume role mappings in mappings.yaml: error unmarshaling JSON: ` +
+				`while decoding JSON: json: unknown field "role"`,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "assume-role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			path := filepath.Join(dir, "mappings.yaml")
+
+			err := ioutil.WriteFile(path, []byte(testData.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mappings, err := LoadAssumeRoleMappings(path)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, strings.Replace(testData.expectedError, "mappings.yaml", path, 1))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedMappings, mappings)
+		})
+	}
+}
+
+type failingProvider struct{}
+
+func (p *failingProvider) Retrieve() (credentials.Value, error) {
+	return credentials.Value{}, errGeneric
+}
+
+func (p *failingProvider) IsExpired() bool {
+	return true
+}
+
+func TestAccountNodeTagger_EnsureInstanceNodeHasTags(t *testing.T) {
+	taggedResult := provider.NewTagResult([]string{"tag1"}, nil)
+
+	testCases := []struct {
+		name            string
+		labels          map[string]string
+		failCredentials bool
+		expectedTagger  string
+		expectedError   string
+	}{
+		{
+			name:           "node matching the node selector",
+			labels:         map[string]string{"team": "machine-learning"},
+			expectedTagger: "selector",
+		},
+		{
+			name:           "node of the account",
+			labels:         map[string]string{constants.AccountIDLabel: otherAccountID},
+			expectedTagger: "account",
+		},
+		{
+			name: "node matching both mappings",
+			labels: map[string]string{
+				"team":                   "machine-learning",
+				constants.AccountIDLabel: otherAccountID,
+			},
+			expectedTagger: "selector",
+		},
+		{
+			name:           "node of the account of node-tagger",
+			labels:         map[string]string{"team": "platform"},
+			expectedTagger: "default",
+		},
+		{
+			name:            "role cannot be assumed",
+			labels:          map[string]string{constants.AccountIDLabel: otherAccountID},
+			failCredentials: true,
+			expectedError:   "failed to assume role arn:aws:iam::123456789012:role/node-tagger for account 123456789012: error",
+		},
+	}
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: testData.labels,
+				},
+			}
+
+			taggers := map[string]*mocks.MockNodeTagger{
+				"default":  mocks.NewMockNodeTagger(ctrl),
+				"selector": mocks.NewMockNodeTagger(ctrl),
+				"account":  mocks.NewMockNodeTagger(ctrl),
+			}
+
+			if testData.expectedTagger != "" {
+				taggers[testData.expectedTagger].
+					EXPECT().
+					EnsureInstanceNodeHasTags(node, inputTags, nil).
+					Return(taggedResult, nil).
+					Times(Once)
+			}
+
+			accountCredentials := credentials.NewStaticCredentials("id", "secret", "")
+			if testData.failCredentials {
+				accountCredentials = credentials.NewCredentials(&failingProvider{})
+			}
+
+			subject, err := NewAccountNodeTagger(taggers["default"], []Account{
+				{
+					Mapping: AssumeRoleMapping{
+						AccountID: "210987654321",
+						RoleARN:   "arn:aws:iam::210987654321:role/node-tagger",
+						NodeSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"team": "machine-learning"},
+						},
+					},
+					Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+					NodeTagger:  taggers["selector"],
+				},
+				{
+					Mapping: AssumeRoleMapping{
+						AccountID: otherAccountID,
+						RoleARN:   otherRoleARN,
+					},
+					Credentials: accountCredentials,
+					NodeTagger:  taggers["account"],
+				},
+			})
+			assert.NoError(t, err)
+
+			errorsBefore := testutil.ToFloat64(metrics.CredentialErrors.WithLabelValues(otherAccountID))
+
+			result, err := subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				assert.Equal(t, errorsBefore+1, testutil.ToFloat64(metrics.CredentialErrors.WithLabelValues(otherAccountID)))
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, taggedResult, result)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"sync"
+	"time"
+)
+
+// batchCall is a single call gathered into a batch, completed by the flush of the batch
+type batchCall struct {
+	input  interface{}
+	output interface{}
+	err    error
+	done   chan struct{}
+}
+
+func (c *batchCall) complete(output interface{}, err error) {
+	c.output = output
+	c.err = err
+	close(c.done)
+}
+
+// batcher gathers the calls made within a window, starting with the first call of a batch, and flushes them together.
+// A batch is flushed early once it holds maxSize calls. The flush must complete every call it is given.
+type batcher struct {
+	window  time.Duration
+	maxSize int
+	flush   func(calls []*batchCall)
+
+	lock    sync.Mutex
+	pending []*batchCall
+	timer   *time.Timer
+}
+
+func newBatcher(window time.Duration, maxSize int, flush func(calls []*batchCall)) *batcher {
+	return &batcher{
+		window:  window,
+		maxSize: maxSize,
+		flush:   flush,
+	}
+}
+
+// do adds the input to the current batch and waits for the batch to be flushed
+func (b *batcher) do(input interface{}) (interface{}, error) {
+	call := &batchCall{
+		input: input,
+		done:  make(chan struct{}),
+	}
+
+	b.lock.Lock()
+
+	b.pending = append(b.pending, call)
+
+	switch {
+	case len(b.pending) >= b.maxSize:
+		calls := b.takePending()
+		b.lock.Unlock()
+
+		b.flush(calls)
+	case len(b.pending) == 1:
+		b.timer = time.AfterFunc(b.window, b.flushPending)
+		b.lock.Unlock()
+	default:
+		b.lock.Unlock()
+	}
+
+	<-call.done
+
+	return call.output, call.err
+}
+
+func (b *batcher) flushPending() {
+	b.lock.Lock()
+	calls := b.takePending()
+	b.lock.Unlock()
+
+	if len(calls) > 0 {
+		b.flush(calls)
+	}
+}
+
+// takePending must be called with the lock held
+func (b *batcher) takePending() []*batchCall {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	calls := b.pending
+	b.pending = nil
+
+	return calls
+}
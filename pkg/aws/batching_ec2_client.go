@@ -0,0 +1,230 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+)
+
+// maxBatchSize is the maximum number of calls in a batch. It is the maximum number of values of a DescribeInstances
+// filter, and well below the maximum number of resources of a CreateTags call.
+const maxBatchSize = 200
+
+// batchingEC2Client gathers the DescribeInstances calls by instance ID and the CreateTags calls made by concurrent
+// reconciles over a short window, and makes a single API call for each batch. Every caller still gets the result
+// and the error of its own call. Any other call goes straight to the EC2 API.
+type batchingEC2Client struct {
+	ec2iface.EC2API
+
+	describeInstances *batcher
+	createTags        *batcher
+}
+
+// NewBatchingEC2Client batches the calls made to the EC2 client within the window
+func NewBatchingEC2Client(ec2Client ec2iface.EC2API, window time.Duration) ec2iface.EC2API {
+	return newBatchingEC2Client(ec2Client, window, maxBatchSize)
+}
+
+func newBatchingEC2Client(ec2Client ec2iface.EC2API, window time.Duration, maxSize int) *batchingEC2Client {
+	b := &batchingEC2Client{
+		EC2API: ec2Client,
+	}
+
+	b.describeInstances = newBatcher(window, maxSize, b.flushDescribeInstances)
+	b.createTags = newBatcher(window, maxSize, b.flushCreateTags)
+
+	return b
+}
+
+// NewBatchingEC2ClientFactory batches the calls of every client created by the factory. Batching is disabled by a
+// zero window.
+func NewBatchingEC2ClientFactory(newEC2Client EC2ClientFactory, window time.Duration) EC2ClientFactory {
+	if window <= 0 {
+		return newEC2Client
+	}
+
+	return func(region string) (ec2iface.EC2API, error) {
+		ec2Client, err := newEC2Client(region)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBatchingEC2Client(ec2Client, window), nil
+	}
+}
+
+func (b *batchingEC2Client) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	// Only lookups by instance ID can be told apart in the output of a batch
+	if len(input.InstanceIds) == 0 || len(input.Filters) > 0 || input.NextToken != nil || input.MaxResults != nil ||
+		input.DryRun != nil {
+		return b.EC2API.DescribeInstances(input)
+	}
+
+	output, err := b.describeInstances.do(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.(*ec2.DescribeInstancesOutput), nil
+}
+
+// flushDescribeInstances describes the instances of every call at once. They are looked up with an instance-id filter
+// rather than by their IDs, so an instance that does not exist does not fail the calls of the other ones.
+func (b *batchingEC2Client) flushDescribeInstances(calls []*batchCall) {
+	instanceIDs := []*string{}
+	seen := map[string]bool{}
+
+	for _, call := range calls {
+		for _, instanceID := range call.input.(*ec2.DescribeInstancesInput).InstanceIds {
+			if !seen[aws.StringValue(instanceID)] {
+				seen[aws.StringValue(instanceID)] = true
+				instanceIDs = append(instanceIDs, instanceID)
+			}
+		}
+	}
+
+	log.V(constants.DebugLogVerbosity).Info("Describing batch of instances.", "Batch.Calls", len(calls),
+		"Batch.Instances", len(instanceIDs))
+
+	reservations := map[string]*ec2.Reservation{}
+
+	err := b.EC2API.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs,
+			},
+		},
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				reservations[aws.StringValue(instance.InstanceId)] = &ec2.Reservation{
+					Groups:        reservation.Groups,
+					OwnerId:       reservation.OwnerId,
+					RequesterId:   reservation.RequesterId,
+					ReservationId: reservation.ReservationId,
+					Instances:     []*ec2.Instance{instance},
+				}
+			}
+		}
+
+		return true
+	})
+
+	for _, call := range calls {
+		if err != nil {
+			call.complete(nil, err)
+			continue
+		}
+
+		call.complete(describeInstancesOutput(call.input.(*ec2.DescribeInstancesInput), reservations))
+	}
+}
+
+// describeInstancesOutput picks the instances of a single call from the ones of its batch. A missing instance fails
+// the call like it would have failed on its own.
+func describeInstancesOutput(input *ec2.DescribeInstancesInput,
+	reservations map[string]*ec2.Reservation) (*ec2.DescribeInstancesOutput, error) {
+	output := &ec2.DescribeInstancesOutput{}
+	missing := []string{}
+
+	for _, instanceID := range input.InstanceIds {
+		reservation, found := reservations[aws.StringValue(instanceID)]
+		if !found {
+			missing = append(missing, aws.StringValue(instanceID))
+			continue
+		}
+
+		output.Reservations = append(output.Reservations, reservation)
+	}
+
+	if len(missing) > 0 {
+		return nil, awserr.New("InvalidInstanceID.NotFound",
+			fmt.Sprintf("The instance IDs '%s' do not exist", strings.Join(missing, ", ")), nil)
+	}
+
+	return output, nil
+}
+
+func (b *batchingEC2Client) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	if input.DryRun != nil {
+		return b.EC2API.CreateTags(input)
+	}
+
+	output, err := b.createTags.do(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.(*ec2.CreateTagsOutput), nil
+}
+
+// flushCreateTags makes a single CreateTags call for the resources of every group of calls applying identical tags
+func (b *batchingEC2Client) flushCreateTags(calls []*batchCall) {
+	groups := map[string][]*batchCall{}
+	fingerprints := []string{}
+
+	for _, call := range calls {
+		fingerprint := tagging.Fingerprint(tagsToMap(call.input.(*ec2.CreateTagsInput).Tags))
+
+		if _, found := groups[fingerprint]; !found {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+
+		groups[fingerprint] = append(groups[fingerprint], call)
+	}
+
+	for _, fingerprint := range fingerprints {
+		b.createGroupTags(groups[fingerprint])
+	}
+}
+
+func (b *batchingEC2Client) createGroupTags(calls []*batchCall) {
+	if len(calls) == 1 {
+		calls[0].complete(b.EC2API.CreateTags(calls[0].input.(*ec2.CreateTagsInput)))
+		return
+	}
+
+	resources := []*string{}
+	for _, call := range calls {
+		resources = append(resources, call.input.(*ec2.CreateTagsInput).Resources...)
+	}
+
+	log.V(constants.DebugLogVerbosity).Info("Tagging batch of resources.", "Batch.Calls", len(calls),
+		"Batch.Resources", len(resources))
+
+	output, err := b.EC2API.CreateTags(&ec2.CreateTagsInput{
+		Resources: resources,
+		Tags:      calls[0].input.(*ec2.CreateTagsInput).Tags,
+	})
+
+	// A single invalid resource fails the whole call, so the calls are retried on their own to fail only the call of
+	// that resource. Any other error, such as throttling, is shared by every call.
+	if awsErr, ok := err.(awserr.Error); ok && strings.HasPrefix(awsErr.Code(), "Invalid") {
+		for _, call := range calls {
+			call.complete(b.EC2API.CreateTags(call.input.(*ec2.CreateTagsInput)))
+		}
+
+		return
+	}
+
+	for _, call := range calls {
+		call.complete(output, err)
+	}
+}
+
+func tagsToMap(tags []*ec2.Tag) map[string]string {
+	result := map[string]string{}
+	for _, tag := range tags {
+		result[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return result
+}
@@ -0,0 +1,247 @@
+package aws
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchWait keeps the batches of the tests from being flushed by their window, so they are only flushed once full
+const batchWait = time.Hour
+
+type batchResult struct {
+	output interface{}
+	err    error
+}
+
+// callConcurrently makes every call at the same time and returns their results in order
+func callConcurrently(calls ...func() (interface{}, error)) []batchResult {
+	results := make([]batchResult, len(calls))
+
+	var wg sync.WaitGroup
+
+	for i, call := range calls {
+		wg.Add(1)
+
+		go func(i int, call func() (interface{}, error)) {
+			defer wg.Done()
+
+			output, err := call()
+			results[i] = batchResult{output: output, err: err}
+		}(i, call)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func describeInstance(subject *batchingEC2Client, instanceID string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return subject.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(instanceID)}})
+	}
+}
+
+func createTags(subject *batchingEC2Client, resourceID string, tags map[string]string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return subject.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(resourceID)},
+			Tags:      convertDesiredTagsToAwsTags(tags),
+		})
+	}
+}
+
+func TestBatchingEC2Client_DescribeInstances_DescribesBatchInOneCall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 3)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeInstancesPages(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(input *ec2.DescribeInstancesInput,
+			fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+			assert.Nil(t, input.InstanceIds)
+			assert.Equal(t, "instance-id", aws.StringValue(input.Filters[0].Name))
+			assert.ElementsMatch(t, []string{"i-one", "i-two", "i-missing"},
+				aws.StringValueSlice(input.Filters[0].Values))
+
+			// Every page holds one of the instances, like a paginated response following the NextToken
+			fn(&ec2.DescribeInstancesOutput{
+				NextToken: aws.String("token"),
+				Reservations: []*ec2.Reservation{
+					{ReservationId: aws.String("r-one"), Instances: []*ec2.Instance{{InstanceId: aws.String("i-one")}}},
+				},
+			}, false)
+			fn(&ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{ReservationId: aws.String("r-two"), Instances: []*ec2.Instance{{InstanceId: aws.String("i-two")}}},
+				},
+			}, true)
+
+			return nil
+		}).
+		Times(Once)
+
+	results := callConcurrently(
+		describeInstance(subject, "i-one"),
+		describeInstance(subject, "i-two"),
+		describeInstance(subject, "i-missing"),
+	)
+
+	for i, instanceID := range []string{"i-one", "i-two"} {
+		assert.NoError(t, results[i].err)
+
+		reservations := results[i].output.(*ec2.DescribeInstancesOutput).Reservations
+		assert.Len(t, reservations, 1)
+		assert.Equal(t, "r-"+instanceID[2:], aws.StringValue(reservations[0].ReservationId))
+		assert.Equal(t, instanceID, aws.StringValue(reservations[0].Instances[0].InstanceId))
+	}
+
+	assert.True(t, isInstanceIDNotFound(results[2].err))
+}
+
+func TestBatchingEC2Client_DescribeInstances_ReturnsBatchErrorToEveryCall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 2)
+
+	mockEc2Client.EXPECT().DescribeInstancesPages(gomock.Any(), gomock.Any()).Return(errGeneric).Times(Once)
+
+	results := callConcurrently(describeInstance(subject, "i-one"), describeInstance(subject, "i-two"))
+
+	assert.EqualError(t, results[0].err, errGeneric.Error())
+	assert.EqualError(t, results[1].err, errGeneric.Error())
+}
+
+func TestBatchingEC2Client_DescribeInstances_DoesNotBatchFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 2)
+
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("private-dns-name"), Values: []*string{aws.String(nodeName)}},
+		},
+	}
+	output := &ec2.DescribeInstancesOutput{}
+
+	mockEc2Client.EXPECT().DescribeInstances(input).Return(output, nil).Times(Once)
+
+	result, err := subject.DescribeInstances(input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, output, result)
+}
+
+func TestBatchingEC2Client_CreateTags_GroupsIdenticalTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 3)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(gomock.Any()).
+		DoAndReturn(func(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+			if tagsToMap(input.Tags)["team"] == "platform" {
+				assert.ElementsMatch(t, []string{"i-one", "i-two"}, aws.StringValueSlice(input.Resources))
+			} else {
+				assert.Equal(t, []string{"i-three"}, aws.StringValueSlice(input.Resources))
+			}
+
+			return &ec2.CreateTagsOutput{}, nil
+		}).
+		Times(2)
+
+	results := callConcurrently(
+		createTags(subject, "i-one", map[string]string{"team": "platform", "env": "prod"}),
+		createTags(subject, "i-two", map[string]string{"env": "prod", "team": "platform"}),
+		createTags(subject, "i-three", map[string]string{"team": "machine-learning"}),
+	)
+
+	for _, result := range results {
+		assert.NoError(t, result.err)
+	}
+}
+
+func TestBatchingEC2Client_CreateTags_RetriesCallsOnTheirOwn_If_ResourceIsInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 2)
+
+	notFoundError := awserr.New("InvalidInstanceID.NotFound", "The instance ID 'i-missing' does not exist", nil)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(gomock.Any()).
+		DoAndReturn(func(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+			for _, resource := range input.Resources {
+				if aws.StringValue(resource) == "i-missing" {
+					return nil, notFoundError
+				}
+			}
+
+			return &ec2.CreateTagsOutput{}, nil
+		}).
+		Times(3)
+
+	results := callConcurrently(
+		createTags(subject, "i-one", inputTags),
+		createTags(subject, "i-missing", inputTags),
+	)
+
+	assert.NoError(t, results[0].err)
+	assert.Equal(t, notFoundError, results[1].err)
+}
+
+func TestBatchingEC2Client_CreateTags_ReturnsBatchErrorToEveryCall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	subject := newBatchingEC2Client(mockEc2Client, batchWait, 2)
+
+	throttlingError := awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil)
+
+	mockEc2Client.EXPECT().CreateTags(gomock.Any()).Return(nil, throttlingError).Times(Once)
+
+	results := callConcurrently(createTags(subject, "i-one", inputTags), createTags(subject, "i-two", inputTags))
+
+	assert.Equal(t, throttlingError, results[0].err)
+	assert.Equal(t, throttlingError, results[1].err)
+}
+
+func TestBatcher_FlushesBatchAfterWindow(t *testing.T) {
+	flushed := make(chan int, 1)
+
+	subject := newBatcher(10*time.Millisecond, maxBatchSize, func(calls []*batchCall) {
+		flushed <- len(calls)
+
+		for _, call := range calls {
+			call.complete(call.input, nil)
+		}
+	})
+
+	output, err := subject.do("input")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "input", output)
+	assert.Equal(t, 1, <-flushed)
+}
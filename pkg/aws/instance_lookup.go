@@ -0,0 +1,205 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/go-logr/logr"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Strategies used to find the instance of a node, in the order they are tried
+const (
+	LookupByProviderID     = "provider-id"
+	LookupByPrivateDNSName = "private-dns-name"
+	LookupByPrivateIP      = "private-ip-address"
+)
+
+// liveInstanceStates are the states of the instances the fallback lookups consider. A terminated instance can still
+// hold the private DNS name or the private IP of the node, which may already belong to its replacement.
+var liveInstanceStates = []string{
+	ec2.InstanceStateNamePending,
+	ec2.InstanceStateNameRunning,
+	ec2.InstanceStateNameStopping,
+	ec2.InstanceStateNameStopped,
+}
+
+// instanceLookup is one way of finding the instance of a node
+type instanceLookup struct {
+	strategy string
+	// description names what the instance is looked up by in errors, e.g. private dns: ip-10-0-0-1.ec2.internal
+	description string
+	input       *ec2.DescribeInstancesInput
+}
+
+// InstanceNotFoundError is returned for nodes whose instance none of the lookups finds, e.g. because it was terminated
+type InstanceNotFoundError struct {
+	// Lookups describes what the instance was looked up by
+	Lookups string
+}
+
+func (e *InstanceNotFoundError) Error() string {
+	return "No instances found for the node with " + e.Lookups
+}
+
+// MultipleInstancesError is returned for nodes whose lookup finds more than one instance, so the one to tag is unknown
+type MultipleInstancesError struct {
+	// Lookup describes what the instances were looked up by
+	Lookup string
+}
+
+func (e *MultipleInstancesError) Error() string {
+	return fmt.Sprintf("More than one instances found with %s. Cannot proceed with tagging", e.Lookup)
+}
+
+// parseProviderID parses the availability zone and the instance ID from a ProviderID of the form
+// aws:///<availability-zone>/<instance-id>. The zone is empty when the ProviderID does not include it.
+func parseProviderID(providerID string) (zone string, instanceID string, err error) {
+	if !strings.HasPrefix(providerID, "aws://") {
+		return "", "", errors.Errorf("invalid AWS ProviderID %q, must be aws:///<availability-zone>/<instance-id>",
+			providerID)
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(providerID, "aws://"), "/"), "/")
+	instanceID = parts[len(parts)-1]
+
+	if len(parts) > 2 || !strings.HasPrefix(instanceID, "i-") {
+		return "", "", errors.Errorf("invalid AWS ProviderID %q, must be aws:///<availability-zone>/<instance-id>",
+			providerID)
+	}
+
+	if len(parts) == 2 {
+		zone = parts[0]
+	}
+
+	return zone, instanceID, nil
+}
+
+// instanceLookups returns the ways of finding the instance of the node. The instance ID from the ProviderID is the
+// most reliable one, and the others are only used when the instance is not found by its ID, e.g. after the instance
+// was replaced. The private DNS name only matches the node name with the default hostnames, and the private IPs are
+// the last resort. Nodes without a ProviderID are never tagged, so there is always an instance ID to start with.
+func instanceLookups(node *corev1.Node) []instanceLookup {
+	lookups := []instanceLookup{}
+
+	if _, instanceID, err := parseProviderID(node.Spec.ProviderID); err == nil {
+		lookups = append(lookups, instanceLookup{
+			strategy:    LookupByProviderID,
+			description: "instance id: " + instanceID,
+			input: &ec2.DescribeInstancesInput{
+				InstanceIds: []*string{aws.String(instanceID)},
+			},
+		})
+	}
+
+	lookups = append(lookups, instanceLookup{
+		strategy:    LookupByPrivateDNSName,
+		description: "private dns: " + node.Name,
+		input: &ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("private-dns-name"),
+					Values: []*string{aws.String(node.Name)},
+				},
+				liveInstancesFilter(),
+			},
+		},
+	})
+
+	privateIPs := []string{}
+
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			privateIPs = append(privateIPs, address.Address)
+		}
+	}
+
+	if len(privateIPs) > 0 {
+		lookups = append(lookups, instanceLookup{
+			strategy:    LookupByPrivateIP,
+			description: "private ips: " + strings.Join(privateIPs, ", "),
+			input: &ec2.DescribeInstancesInput{
+				Filters: []*ec2.Filter{
+					{
+						Name:   aws.String("private-ip-address"),
+						Values: aws.StringSlice(privateIPs),
+					},
+					liveInstancesFilter(),
+				},
+			},
+		})
+	}
+
+	return lookups
+}
+
+// liveInstancesFilter leaves out the instances that are shutting down or terminated
+func liveInstancesFilter() *ec2.Filter {
+	return &ec2.Filter{
+		Name:   aws.String("instance-state-name"),
+		Values: aws.StringSlice(liveInstanceStates),
+	}
+}
+
+// findInstance tries every lookup of the node in turn and returns the instance of the first one finding exactly one
+func findInstance(ec2Client ec2iface.EC2API, nodeLogger logr.Logger, node *corev1.Node) (*ec2.Instance, error) {
+	lookups := instanceLookups(node)
+	descriptions := []string{}
+
+	for _, lookup := range lookups {
+		descriptions = append(descriptions, lookup.description)
+
+		describeInstancesOutput, err := ec2Client.DescribeInstances(lookup.input)
+		if err != nil {
+			// An instance ID that does not exist is an error rather than an empty result
+			if lookup.strategy == LookupByProviderID && isInstanceIDNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		instances := []*ec2.Instance{}
+		for _, reservation := range describeInstancesOutput.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+
+		switch {
+		case len(instances) == 1:
+			lookupLogger := nodeLogger.WithValues("Instance.ID", aws.StringValue(instances[0].InstanceId),
+				"Lookup.Strategy", lookup.strategy)
+
+			if lookup.strategy == LookupByProviderID {
+				lookupLogger.V(constants.DebugLogVerbosity).Info("Found instance.")
+			} else {
+				lookupLogger.Info("Found instance with a fallback lookup.")
+			}
+
+			return instances[0], nil
+		case len(instances) > 1:
+			return nil, &MultipleInstancesError{Lookup: lookup.description}
+		}
+	}
+
+	return nil, &InstanceNotFoundError{Lookups: joinDescriptions(descriptions)}
+}
+
+func isInstanceIDNotFound(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+
+	return ok && (awsErr.Code() == "InvalidInstanceID.NotFound" || awsErr.Code() == "InvalidInstanceID.Malformed")
+}
+
+func joinDescriptions(descriptions []string) string {
+	if len(descriptions) == 1 {
+		return descriptions[0]
+	}
+
+	return strings.Join(descriptions[:len(descriptions)-1], ", ") + " or " + descriptions[len(descriptions)-1]
+}
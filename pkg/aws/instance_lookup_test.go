@@ -0,0 +1,224 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseProviderID(t *testing.T) {
+	testCases := []struct {
+		name               string
+		providerID         string
+		expectedZone       string
+		expectedInstanceID string
+		expectedError      string
+	}{
+		{
+			name:               "zone and instance id",
+			providerID:         "aws:///eu-west-1a/i-0123456789abcdef0",
+			expectedZone:       "eu-west-1a",
+			expectedInstanceID: "i-0123456789abcdef0",
+		},
+		{
+			name:               "instance id only",
+			providerID:         "aws:///i-0123456789abcdef0",
+			expectedInstanceID: "i-0123456789abcdef0",
+		},
+		{
+			name:       "other provider",
+			providerID: "gce://project/zone/instance",
+			expectedError: `invalid AWS ProviderID "gce://project/zone/instance", ` +
+				"must be aws:///<availability-zone>/<instance-id>",
+		},
+		{
+			name:       "not an instance",
+			providerID: "aws:///eu-west-1a/fargate-ip-10-0-0-1",
+			expectedError: `invalid AWS ProviderID "aws:///eu-west-1a/fargate-ip-10-0-0-1", ` +
+				"must be aws:///<availability-zone>/<instance-id>",
+		},
+		{
+			name:       "too many segments",
+			providerID: "aws:///eu-west-1/eu-west-1a/i-0123456789abcdef0",
+			expectedError: `invalid AWS ProviderID "aws:///eu-west-1/eu-west-1a/i-0123456789abcdef0", ` +
+				"must be aws:///<availability-zone>/<instance-id>",
+		},
+	}
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			zone, instanceID, err := parseProviderID(testData.providerID)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedZone, zone)
+			assert.Equal(t, testData.expectedInstanceID, instanceID)
+		})
+	}
+}
+
+func TestFindInstance(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///eu-west-1a/" + instanceID,
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: nodeName},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+
+	liveStates := aws.StringSlice([]string{"pending", "running", "stopping", "stopped"})
+	byInstanceID := &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(instanceID)},
+	}
+	byPrivateDNSName := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("private-dns-name"), Values: []*string{aws.String(nodeName)}},
+			{Name: aws.String("instance-state-name"), Values: liveStates},
+		},
+	}
+	byPrivateIP := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("private-ip-address"), Values: []*string{aws.String("10.0.0.1")}},
+			{Name: aws.String("instance-state-name"), Values: liveStates},
+		},
+	}
+
+	found := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{Instances: []*ec2.Instance{{InstanceId: aws.String(instanceID)}}},
+		},
+	}
+	notFound := &ec2.DescribeInstancesOutput{}
+	notFoundError := awserr.New("InvalidInstanceID.NotFound", "The instance ID does not exist", nil)
+
+	type describeCall struct {
+		input  *ec2.DescribeInstancesInput
+		output *ec2.DescribeInstancesOutput
+		err    error
+	}
+
+	testCases := []struct {
+		name          string
+		node          *corev1.Node
+		calls         []describeCall
+		expectedError string
+	}{
+		{
+			name:  "found by instance id",
+			node:  node,
+			calls: []describeCall{{input: byInstanceID, output: found}},
+		},
+		{
+			name: "instance id not found falls back to the private dns name",
+			node: node,
+			calls: []describeCall{
+				{input: byInstanceID, err: notFoundError},
+				{input: byPrivateDNSName, output: found},
+			},
+		},
+		{
+			name: "falls back to the private ips",
+			node: node,
+			calls: []describeCall{
+				{input: byInstanceID, output: notFound},
+				{input: byPrivateDNSName, output: notFound},
+				{input: byPrivateIP, output: found},
+			},
+		},
+		{
+			name: "not found by any lookup",
+			node: node,
+			calls: []describeCall{
+				{input: byInstanceID, err: notFoundError},
+				{input: byPrivateDNSName, output: notFound},
+				{input: byPrivateIP, output: notFound},
+			},
+			expectedError: "No instances found for the node with instance id: i-some-id, " +
+				"private dns: ip-some-private-ip.region.compute.internal or private ips: 10.0.0.1",
+		},
+		{
+			name:          "instance id lookup fails",
+			node:          node,
+			calls:         []describeCall{{input: byInstanceID, err: errGeneric}},
+			expectedError: errGeneric.Error(),
+		},
+	}
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+			expectedCalls := []*gomock.Call{}
+			for _, call := range testData.calls {
+				expectedCalls = append(expectedCalls, mockEc2Client.
+					EXPECT().
+					DescribeInstances(call.input).
+					Return(call.output, call.err).
+					Times(Once))
+			}
+
+			gomock.InOrder(expectedCalls...)
+
+			instance, err := findInstance(mockEc2Client, log, testData.node)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, instanceID, aws.StringValue(instance.InstanceId))
+		})
+	}
+}
+
+func TestInstanceLookups_OnlyFallBackToLiveInstances(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///eu-west-1a/" + instanceID,
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+
+	lookups := instanceLookups(node)
+
+	assert.Len(t, lookups, 3)
+	assert.Empty(t, lookups[0].input.Filters)
+
+	for _, lookup := range lookups[1:] {
+		assert.Contains(t, lookup.input.Filters, &ec2.Filter{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+		}, lookup.strategy)
+	}
+}
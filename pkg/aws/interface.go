@@ -1,12 +1,21 @@
 package aws
 
 import (
-	corev1 "k8s.io/api/core/v1"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
 )
 
 //nolint
-//go:generate mockgen -package=mocks -destination ../mocks/mock_instance_tagger.go github.com/ouzi-dev/node-tagger/pkg/aws NodeTagger
+//go:generate mockgen -package=mocks -destination ../mocks/mock_resource_tagger.go github.com/ouzi-dev/node-tagger/pkg/aws ResourceTagger
 
-type NodeTagger interface {
-	EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string) error
+// ResourceTagger tags a single EC2 resource, such as a volume or a snapshot, by ID
+type ResourceTagger interface {
+	EnsureResourceHasTags(resourceID string, tags map[string]string, conflictPolicies tagging.ConflictPolicies) error
+}
+
+//nolint
+//go:generate mockgen -package=mocks -destination ../mocks/mock_load_balancer_tagger.go github.com/ouzi-dev/node-tagger/pkg/aws LoadBalancerTagger
+
+// LoadBalancerTagger tags the load balancer answering on a DNS name, such as the hostname of a service
+type LoadBalancerTagger interface {
+	EnsureLoadBalancerHasTags(dnsName string, tags map[string]string, conflictPolicies tagging.ConflictPolicies) error
 }
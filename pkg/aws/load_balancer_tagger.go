@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/pkg/errors"
+)
+
+//nolint
+//go:generate mockgen -package=mocks -destination ../mocks/mock_elbiface.go github.com/aws/aws-sdk-go/service/elb/elbiface ELBAPI
+//nolint
+//go:generate mockgen -package=mocks -destination ../mocks/mock_elbv2iface.go github.com/aws/aws-sdk-go/service/elbv2/elbv2iface ELBV2API
+
+// loadBalancerTagger remembers the load balancer of every DNS name it saw while paging through the load balancers, so
+// they are only paged through again for DNS names it does not know, e.g. of a new load balancer
+type loadBalancerTagger struct {
+	elbClient   elbiface.ELBAPI
+	elbv2Client elbv2iface.ELBV2API
+
+	lock sync.Mutex
+	// loadBalancerArns are the ARNs of the network and application load balancers by lower case DNS name
+	loadBalancerArns map[string]*string
+	// classicLoadBalancerNames are the names of the classic load balancers by lower case DNS name
+	classicLoadBalancerNames map[string]*string
+}
+
+func NewLoadBalancerTagger(elbClient elbiface.ELBAPI, elbv2Client elbv2iface.ELBV2API) LoadBalancerTagger {
+	return &loadBalancerTagger{
+		elbClient:                elbClient,
+		elbv2Client:              elbv2Client,
+		loadBalancerArns:         map[string]*string{},
+		classicLoadBalancerNames: map[string]*string{},
+	}
+}
+
+// EnsureLoadBalancerHasTags looks the load balancer up among the network and application load balancers first, and
+// among the classic ones after, as both kinds can be created for services
+func (l *loadBalancerTagger) EnsureLoadBalancerHasTags(dnsName string, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) error {
+	loadBalancerLogger := log.WithValues("LoadBalancer.DNSName", dnsName)
+
+	loadBalancerArn, loadBalancerName, err := l.findLoadBalancer(dnsName)
+	if err != nil {
+		return err
+	}
+
+	if loadBalancerArn != nil {
+		describeTagsOutput, err := l.elbv2Client.DescribeTags(&elbv2.DescribeTagsInput{
+			ResourceArns: []*string{loadBalancerArn},
+		})
+		if err != nil {
+			// The load balancer may be gone, so it is looked up again next time
+			l.forgetLoadBalancer(dnsName)
+			return err
+		}
+
+		existingTags := []*ec2.Tag{}
+		for _, description := range describeTagsOutput.TagDescriptions {
+			for _, tag := range description.Tags {
+				existingTags = append(existingTags, &ec2.Tag{Key: tag.Key, Value: tag.Value})
+			}
+		}
+
+		return applyTagChanges(&elbv2TagWriter{elbv2Client: l.elbv2Client, loadBalancerArn: loadBalancerArn},
+			loadBalancerLogger.WithValues("LoadBalancer.Arn", *loadBalancerArn), existingTags, constants.ManagedKeysTag,
+			tags, conflictPolicies)
+	}
+
+	if loadBalancerName == nil {
+		return errors.Errorf("No load balancer found with dns name: %s", dnsName)
+	}
+
+	describeTagsOutput, err := l.elbClient.DescribeTags(&elb.DescribeTagsInput{
+		LoadBalancerNames: []*string{loadBalancerName},
+	})
+	if err != nil {
+		l.forgetLoadBalancer(dnsName)
+		return err
+	}
+
+	existingTags := []*ec2.Tag{}
+	for _, description := range describeTagsOutput.TagDescriptions {
+		for _, tag := range description.Tags {
+			existingTags = append(existingTags, &ec2.Tag{Key: tag.Key, Value: tag.Value})
+		}
+	}
+
+	return applyTagChanges(&elbTagWriter{elbClient: l.elbClient, loadBalancerName: loadBalancerName},
+		loadBalancerLogger.WithValues("LoadBalancer.Name", *loadBalancerName), existingTags, constants.ManagedKeysTag,
+		tags, conflictPolicies)
+}
+
+// findLoadBalancer returns either the ARN of the network or application load balancer with the DNS name, or the name
+// of the classic one. The load balancers are only paged through when the DNS name is not known yet, and the DNS names
+// of every load balancer paged through are remembered.
+func (l *loadBalancerTagger) findLoadBalancer(dnsName string) (*string, *string, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := strings.ToLower(dnsName)
+
+	if loadBalancerArn, found := l.loadBalancerArns[key]; found {
+		return loadBalancerArn, nil, nil
+	}
+
+	if loadBalancerName, found := l.classicLoadBalancerNames[key]; found {
+		return nil, loadBalancerName, nil
+	}
+
+	loadBalancerArns := map[string]*string{}
+
+	err := l.elbv2Client.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{},
+		func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range page.LoadBalancers {
+				loadBalancerArns[strings.ToLower(aws.StringValue(loadBalancer.DNSName))] = loadBalancer.LoadBalancerArn
+			}
+
+			return true
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l.loadBalancerArns = loadBalancerArns
+
+	if loadBalancerArn, found := l.loadBalancerArns[key]; found {
+		return loadBalancerArn, nil, nil
+	}
+
+	classicLoadBalancerNames := map[string]*string{}
+
+	err = l.elbClient.DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{},
+		func(page *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range page.LoadBalancerDescriptions {
+				classicLoadBalancerNames[strings.ToLower(aws.StringValue(loadBalancer.DNSName))] =
+					loadBalancer.LoadBalancerName
+			}
+
+			return true
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l.classicLoadBalancerNames = classicLoadBalancerNames
+
+	return nil, l.classicLoadBalancerNames[key], nil
+}
+
+func (l *loadBalancerTagger) forgetLoadBalancer(dnsName string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.loadBalancerArns, strings.ToLower(dnsName))
+	delete(l.classicLoadBalancerNames, strings.ToLower(dnsName))
+}
+
+// elbTagWriter writes the tags of a single classic load balancer
+type elbTagWriter struct {
+	elbClient        elbiface.ELBAPI
+	loadBalancerName *string
+}
+
+func (w *elbTagWriter) deleteTags(keys []string) error {
+	tagKeys := []*elb.TagKeyOnly{}
+	for _, key := range keys {
+		tagKeys = append(tagKeys, &elb.TagKeyOnly{Key: aws.String(key)})
+	}
+
+	_, err := w.elbClient.RemoveTags(&elb.RemoveTagsInput{
+		LoadBalancerNames: []*string{w.loadBalancerName},
+		Tags:              tagKeys,
+	})
+
+	return err
+}
+
+func (w *elbTagWriter) createTags(tags map[string]string) error {
+	elbTags := []*elb.Tag{}
+	for _, key := range sortedKeys(tags) {
+		elbTags = append(elbTags, &elb.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err := w.elbClient.AddTags(&elb.AddTagsInput{
+		LoadBalancerNames: []*string{w.loadBalancerName},
+		Tags:              elbTags,
+	})
+
+	return err
+}
+
+// elbv2TagWriter writes the tags of a single network or application load balancer
+type elbv2TagWriter struct {
+	elbv2Client     elbv2iface.ELBV2API
+	loadBalancerArn *string
+}
+
+func (w *elbv2TagWriter) deleteTags(keys []string) error {
+	_, err := w.elbv2Client.RemoveTags(&elbv2.RemoveTagsInput{
+		ResourceArns: []*string{w.loadBalancerArn},
+		TagKeys:      aws.StringSlice(keys),
+	})
+
+	return err
+}
+
+func (w *elbv2TagWriter) createTags(tags map[string]string) error {
+	elbv2Tags := []*elbv2.Tag{}
+	for _, key := range sortedKeys(tags) {
+		elbv2Tags = append(elbv2Tags, &elbv2.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	_, err := w.elbv2Client.AddTags(&elbv2.AddTagsInput{
+		ResourceArns: []*string{w.loadBalancerArn},
+		Tags:         elbv2Tags,
+	})
+
+	return err
+}
@@ -0,0 +1,354 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	loadBalancerDNSName = "a1b2c3-0123456789.elb.eu-west-1.amazonaws.com"
+	loadBalancerArn     = "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/a1b2c3/0123456789"
+	loadBalancerName    = "a1b2c3"
+)
+
+func describeLoadBalancersPages(pages ...[]*elbv2.LoadBalancer) func(*elbv2.DescribeLoadBalancersInput,
+	func(*elbv2.DescribeLoadBalancersOutput, bool) bool) error {
+	return func(input *elbv2.DescribeLoadBalancersInput, fn func(*elbv2.DescribeLoadBalancersOutput, bool) bool) error {
+		for i, page := range pages {
+			if !fn(&elbv2.DescribeLoadBalancersOutput{LoadBalancers: page}, i == len(pages)-1) {
+				break
+			}
+		}
+
+		return nil
+	}
+}
+
+func describeClassicLoadBalancersPages(pages ...[]*elb.LoadBalancerDescription) func(*elb.DescribeLoadBalancersInput,
+	func(*elb.DescribeLoadBalancersOutput, bool) bool) error {
+	return func(input *elb.DescribeLoadBalancersInput, fn func(*elb.DescribeLoadBalancersOutput, bool) bool) error {
+		for i, page := range pages {
+			if !fn(&elb.DescribeLoadBalancersOutput{LoadBalancerDescriptions: page}, i == len(pages)-1) {
+				break
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestEnsureLoadBalancerHasTags_ReturnsError_If_DescribeLoadBalancers_Returns_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		Return(errGeneric).
+		Times(Once)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.EqualError(t, err, errGeneric.Error())
+}
+
+func TestEnsureLoadBalancerHasTags_ReturnsError_If_LoadBalancerNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages(
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String("other.elb.eu-west-1.amazonaws.com"), LoadBalancerArn: aws.String("arn")},
+			},
+		)).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeClassicLoadBalancersPages()).
+		Times(Once)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.EqualError(t, err, "No load balancer found with dns name: "+loadBalancerDNSName)
+}
+
+func TestEnsureLoadBalancerHasTags_TagsNetworkLoadBalancer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages(
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String("other.elb.eu-west-1.amazonaws.com"), LoadBalancerArn: aws.String("arn")},
+			},
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String(loadBalancerDNSName), LoadBalancerArn: aws.String(loadBalancerArn)},
+			},
+		)).
+		Times(Once)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: []*string{aws.String(loadBalancerArn)}}).
+		Return(&elbv2.DescribeTagsOutput{
+			TagDescriptions: []*elbv2.TagDescription{
+				{
+					ResourceArn: aws.String(loadBalancerArn),
+					Tags: []*elbv2.Tag{
+						{Key: aws.String("tag1"), Value: aws.String("value1")},
+						{Key: aws.String("removed"), Value: aws.String("value")},
+						{Key: aws.String("kubernetes.io/service-name"), Value: aws.String("default/ingress")},
+						{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("removed,tag1")},
+					},
+				},
+			},
+		}, nil).
+		Times(Once)
+
+	gomock.InOrder(
+		mockElbv2Client.
+			EXPECT().
+			RemoveTags(&elbv2.RemoveTagsInput{
+				ResourceArns: []*string{aws.String(loadBalancerArn)},
+				TagKeys:      []*string{aws.String("removed")},
+			}).
+			Return(nil, nil).
+			Times(Once),
+		mockElbv2Client.
+			EXPECT().
+			AddTags(&elbv2.AddTagsInput{
+				ResourceArns: []*string{aws.String(loadBalancerArn)},
+				Tags: []*elbv2.Tag{
+					{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+					{Key: aws.String("tag2"), Value: aws.String("value2")},
+				},
+			}).
+			Return(nil, nil).
+			Times(Once),
+	)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestEnsureLoadBalancerHasTags_ReturnsNoError_If_ClassicLoadBalancerAlreadyTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages()).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeClassicLoadBalancersPages(
+			[]*elb.LoadBalancerDescription{
+				{DNSName: aws.String(loadBalancerDNSName), LoadBalancerName: aws.String(loadBalancerName)},
+			},
+		)).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeTags(&elb.DescribeTagsInput{LoadBalancerNames: []*string{aws.String(loadBalancerName)}}).
+		Return(&elb.DescribeTagsOutput{
+			TagDescriptions: []*elb.TagDescription{
+				{
+					LoadBalancerName: aws.String(loadBalancerName),
+					Tags: []*elb.Tag{
+						{Key: aws.String("tag1"), Value: aws.String("value1")},
+						{Key: aws.String("tag2"), Value: aws.String("value2")},
+						{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+					},
+				},
+			},
+		}, nil).
+		Times(Once)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestEnsureLoadBalancerHasTags_TagsClassicLoadBalancer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages()).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeClassicLoadBalancersPages(
+			[]*elb.LoadBalancerDescription{
+				{DNSName: aws.String(loadBalancerDNSName), LoadBalancerName: aws.String(loadBalancerName)},
+			},
+		)).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeTags(&elb.DescribeTagsInput{LoadBalancerNames: []*string{aws.String(loadBalancerName)}}).
+		Return(&elb.DescribeTagsOutput{}, nil).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		AddTags(&elb.AddTagsInput{
+			LoadBalancerNames: []*string{aws.String(loadBalancerName)},
+			Tags: []*elb.Tag{
+				{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+				{Key: aws.String("tag1"), Value: aws.String("value1")},
+				{Key: aws.String("tag2"), Value: aws.String("value2")},
+			},
+		}).
+		Return(nil, errGeneric).
+		Times(Once)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.EqualError(t, err, errGeneric.Error())
+}
+
+func TestEnsureLoadBalancerHasTags_PagesThroughLoadBalancersOnce_For_KnownDNSNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	otherDNSName := "d4e5f6-0123456789.elb.eu-west-1.amazonaws.com"
+	otherArn := "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/d4e5f6/0123456789"
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages(
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String(loadBalancerDNSName), LoadBalancerArn: aws.String(loadBalancerArn)},
+			},
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String(otherDNSName), LoadBalancerArn: aws.String(otherArn)},
+			},
+		)).
+		Times(Once)
+
+	for _, arn := range []string{loadBalancerArn, otherArn} {
+		mockElbv2Client.
+			EXPECT().
+			DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: []*string{aws.String(arn)}}).
+			Return(&elbv2.DescribeTagsOutput{
+				TagDescriptions: []*elbv2.TagDescription{
+					{
+						ResourceArn: aws.String(arn),
+						Tags: []*elbv2.Tag{
+							{Key: aws.String("tag1"), Value: aws.String("value1")},
+							{Key: aws.String("tag2"), Value: aws.String("value2")},
+							{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+						},
+					},
+				},
+			}, nil).
+			Times(2)
+	}
+
+	for _, dnsName := range []string{loadBalancerDNSName, otherDNSName, loadBalancerDNSName, otherDNSName} {
+		err := subject.EnsureLoadBalancerHasTags(dnsName, inputTags, nil)
+		assert.NoError(t, err)
+	}
+}
+
+func TestEnsureLoadBalancerHasTags_LooksLoadBalancerUpAgain_If_DescribeTags_Returns_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockElbClient := mocks.NewMockELBAPI(ctrl)
+	mockElbv2Client := mocks.NewMockELBV2API(ctrl)
+
+	subject := NewLoadBalancerTagger(mockElbClient, mockElbv2Client)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages(
+			[]*elbv2.LoadBalancer{
+				{DNSName: aws.String(loadBalancerDNSName), LoadBalancerArn: aws.String(loadBalancerArn)},
+			},
+		)).
+		Times(Once)
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: []*string{aws.String(loadBalancerArn)}}).
+		Return(nil, errGeneric).
+		Times(Once)
+
+	err := subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.EqualError(t, err, errGeneric.Error())
+
+	mockElbv2Client.
+		EXPECT().
+		DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeLoadBalancersPages()).
+		Times(Once)
+
+	mockElbClient.
+		EXPECT().
+		DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, gomock.Any()).
+		DoAndReturn(describeClassicLoadBalancersPages()).
+		Times(Once)
+
+	err = subject.EnsureLoadBalancerHasTags(loadBalancerDNSName, inputTags, nil)
+
+	assert.EqualError(t, err, "No load balancer found with dns name: "+loadBalancerDNSName)
+}
@@ -0,0 +1,251 @@
+package aws
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/go-logr/logr"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+)
+
+// managedKeysTags are the tags recording the keys applied from each source of tags of a resource, as a volume is
+// tagged both from its node and from its PersistentVolumeClaim. Each source leaves the tags of the others alone.
+var managedKeysTags = []string{constants.ManagedKeysTag, constants.VolumeManagedKeysTag}
+
+// tagChanges are the changes needed to bring the tags of a resource to the desired state
+type tagChanges struct {
+	// managedKeysTag is the tag recording the keys of the tags node-tagger applied to the resource
+	managedKeysTag string
+	// toCreate are the tags to create or overwrite, including the updated managed keys tags
+	toCreate map[string]string
+	// toDelete are the keys of the managed tags that are no longer desired
+	toDelete []string
+	// toDeleteManagedKeysTags are the managed keys tags no longer needed to record the keys, deleted once the others
+	// are written so the keys are always recorded somewhere
+	toDeleteManagedKeysTags []string
+}
+
+func (c *tagChanges) empty() bool {
+	return len(c.toCreate) == 0 && len(c.toDelete) == 0 && len(c.toDeleteManagedKeysTags) == 0
+}
+
+// result records the keys of the changed tags, leaving out the managed keys tags node-tagger keeps for itself
+func (c *tagChanges) result() provider.TagResult {
+	setKeys := []string{}
+
+	for key := range c.toCreate {
+		if !tagging.IsManagedKeysTag(c.managedKeysTag, key) {
+			setKeys = append(setKeys, key)
+		}
+	}
+
+	return provider.NewTagResult(setKeys, c.toDelete)
+}
+
+// applyTo returns the tags of a resource once the changes are made to its existing tags
+func (c *tagChanges) applyTo(existingTags []*ec2.Tag) []*ec2.Tag {
+	deleted := map[string]bool{}
+	for _, key := range append(append([]string{}, c.toDelete...), c.toDeleteManagedKeysTags...) {
+		deleted[key] = true
+	}
+
+	tags := []*ec2.Tag{}
+
+	for _, tag := range existingTags {
+		key := aws.StringValue(tag.Key)
+		if _, created := c.toCreate[key]; !created && !deleted[key] {
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, key := range sortedKeys(c.toCreate) {
+		tags = append(tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(c.toCreate[key])})
+	}
+
+	return tags
+}
+
+// planTagChanges compares the existing tags of a resource with the desired ones. Only the keys recorded in the
+// managed keys tags named after managedKeysTag are ever deleted, so tags applied by anyone else are left untouched. A
+// key is recorded only when node-tagger creates the tag, or when it is already recorded, never when the tag existed
+// before. An existing tag with a different value that node-tagger did not apply is a conflict, resolved with the
+// conflict policy of its key. Tags recorded by another source of tags of the resource are neither written nor
+// recorded, so the sources never overwrite each other.
+func planTagChanges(existingTags []*ec2.Tag, managedKeysTag string, desiredTags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (*tagChanges, error) {
+	existing := map[string]string{}
+	for _, tag := range existingTags {
+		existing[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	managedKeys := tagging.ParseManagedKeys(managedKeysTag, existing)
+
+	managed := map[string]bool{}
+	for _, key := range managedKeys {
+		managed[key] = true
+	}
+
+	ownedElsewhere := keysOwnedElsewhere(existing, managedKeysTag, managed)
+
+	changes := &tagChanges{
+		managedKeysTag:          managedKeysTag,
+		toCreate:                map[string]string{},
+		toDelete:                []string{},
+		toDeleteManagedKeysTags: []string{},
+	}
+	owned := map[string]string{}
+	conflicts := []tagging.Conflict{}
+
+	for _, key := range sortedKeys(desiredTags) {
+		value := desiredTags[key]
+		existingValue, found := existing[key]
+
+		if ownedElsewhere[key] {
+			log.V(constants.DebugLogVerbosity).Info("Leaving tag applied from another source.", "Tag.Key", key)
+			continue
+		}
+
+		if found && existingValue != value && !managed[key] {
+			switch conflictPolicies.For(key) {
+			case tagging.ConflictPolicyKeepExisting:
+				log.V(constants.DebugLogVerbosity).Info("Keeping existing tag value.", "Tag.Key", key)
+				continue
+			case tagging.ConflictPolicyFail:
+				conflicts = append(conflicts, tagging.Conflict{Key: key, ExistingValue: existingValue, DesiredValue: value})
+				continue
+			}
+		}
+
+		// Tags applied by anyone else stay theirs, even when they are overwritten
+		if !found || managed[key] {
+			owned[key] = value
+		}
+
+		if !found || existingValue != value {
+			changes.toCreate[key] = value
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &tagging.ConflictError{Conflicts: conflicts}
+	}
+
+	for _, key := range managedKeys {
+		if _, desired := owned[key]; desired || ownedElsewhere[key] {
+			continue
+		}
+
+		if _, found := existing[key]; found {
+			changes.toDelete = append(changes.toDelete, key)
+		}
+	}
+
+	managedKeysTags, err := tagging.FormatManagedKeys(managedKeysTag, sortedKeys(owned))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range managedKeysTags {
+		if existingValue, found := existing[key]; !found || existingValue != value {
+			changes.toCreate[key] = value
+		}
+	}
+
+	for _, key := range sortedKeys(existing) {
+		if _, needed := managedKeysTags[key]; tagging.IsManagedKeysTag(managedKeysTag, key) && !needed {
+			changes.toDeleteManagedKeysTags = append(changes.toDeleteManagedKeysTags, key)
+		}
+	}
+
+	return changes, nil
+}
+
+// keysOwnedElsewhere returns the keys recorded by the other sources of tags of the resource. A key recorded by more
+// than one source belongs to the first of them in managedKeysTags, so exactly one of them keeps it.
+func keysOwnedElsewhere(existing map[string]string, managedKeysTag string, managed map[string]bool) map[string]bool {
+	ownedElsewhere := map[string]bool{}
+	precedes := true
+
+	for _, otherManagedKeysTag := range managedKeysTags {
+		if otherManagedKeysTag == managedKeysTag {
+			precedes = false
+			continue
+		}
+
+		for _, key := range tagging.ParseManagedKeys(otherManagedKeysTag, existing) {
+			if precedes || !managed[key] {
+				ownedElsewhere[key] = true
+			}
+		}
+	}
+
+	return ownedElsewhere
+}
+
+// tagWriter writes the tags of a single resource through the API the resource belongs to
+type tagWriter interface {
+	deleteTags(keys []string) error
+	createTags(tags map[string]string) error
+}
+
+// applyTagChanges brings the existing tags of a single resource to the desired state, skipping any API call when
+// the resource is already tagged
+func applyTagChanges(writer tagWriter, resourceLogger logr.Logger, existingTags []*ec2.Tag, managedKeysTag string,
+	tags map[string]string, conflictPolicies tagging.ConflictPolicies) error {
+	changes, err := planTagChanges(existingTags, managedKeysTag, tags, conflictPolicies)
+	if err != nil {
+		return err
+	}
+
+	return writeTagChanges(writer, resourceLogger, changes)
+}
+
+// writeTagChanges makes the planned changes to the tags of a single resource
+func writeTagChanges(writer tagWriter, resourceLogger logr.Logger, changes *tagChanges) error {
+	if changes.empty() {
+		resourceLogger.V(constants.DebugLogVerbosity).Info("Resource already tagged.")
+		return nil
+	}
+
+	// Tags that are no longer desired are removed first so the managed keys tag still lists them if this fails
+	if len(changes.toDelete) > 0 {
+		resourceLogger.Info("Removing tags from resource.", "Tag.Keys", changes.toDelete)
+
+		err := writer.deleteTags(changes.toDelete)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(changes.toCreate) > 0 {
+		resourceLogger.Info("Tagging resource.")
+
+		err := writer.createTags(changes.toCreate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(changes.toDeleteManagedKeysTags) > 0 {
+		err := writer.deleteTags(changes.toDeleteManagedKeysTags)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,365 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAwsTags(tags map[string]string) []*ec2.Tag {
+	return convertDesiredTagsToAwsTags(tags)
+}
+
+func TestPlanTagChanges(t *testing.T) {
+	tests := []struct {
+		testName         string
+		existingTags     map[string]string
+		desiredTags      map[string]string
+		conflictPolicies tagging.ConflictPolicies
+		expectedToCreate map[string]string
+		expectedToDelete []string
+		// expectedToDeleteManagedKeysTags defaults to none
+		expectedToDeleteManagedKeysTags []string
+	}{
+		{
+			testName:     "untagged resource",
+			existingTags: map[string]string{},
+			desiredTags:  map[string]string{"tag1": "value1", "tag2": "value2"},
+			expectedToCreate: map[string]string{
+				"tag1":                   "value1",
+				"tag2":                   "value2",
+				constants.ManagedKeysTag: "tag1,tag2",
+			},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "already tagged resource",
+			existingTags: map[string]string{
+				"tag1":                   "value1",
+				"other":                  "value",
+				constants.ManagedKeysTag: "tag1",
+			},
+			desiredTags:      map[string]string{"tag1": "value1"},
+			expectedToCreate: map[string]string{},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "resource tagged before ownership tracking",
+			existingTags: map[string]string{
+				"tag1": "value1",
+			},
+			desiredTags:      map[string]string{"tag1": "value1"},
+			expectedToCreate: map[string]string{},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "existing tag with the desired value is not managed",
+			existingTags: map[string]string{
+				"tag1": "value1",
+			},
+			desiredTags: map[string]string{"tag1": "value1", "tag2": "value2"},
+			expectedToCreate: map[string]string{
+				"tag2":                   "value2",
+				constants.ManagedKeysTag: "tag2",
+			},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "changed value",
+			existingTags: map[string]string{
+				"tag1":                   "old",
+				constants.ManagedKeysTag: "tag1",
+			},
+			desiredTags:      map[string]string{"tag1": "new"},
+			expectedToCreate: map[string]string{"tag1": "new"},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "managed tags no longer desired",
+			existingTags: map[string]string{
+				"tag1":                   "value1",
+				"tag2":                   "value2",
+				"tag2-unmanaged":         "value2",
+				"tag":                    "unmanaged",
+				constants.ManagedKeysTag: "tag1,tag2,tag3",
+			},
+			desiredTags: map[string]string{"tag1": "value1"},
+			expectedToCreate: map[string]string{
+				constants.ManagedKeysTag: "tag1",
+			},
+			expectedToDelete: []string{"tag2"},
+		},
+		{
+			testName: "no desired tags",
+			existingTags: map[string]string{
+				"tag1":                   "value1",
+				constants.ManagedKeysTag: "tag1",
+			},
+			desiredTags:                     map[string]string{},
+			expectedToCreate:                map[string]string{},
+			expectedToDelete:                []string{"tag1"},
+			expectedToDeleteManagedKeysTags: []string{constants.ManagedKeysTag},
+		},
+		{
+			testName: "keys overflowing into a numbered managed keys tag",
+			existingTags: map[string]string{
+				strings.Repeat("a", 128): "value",
+				constants.ManagedKeysTag: strings.Repeat("a", 128),
+			},
+			desiredTags: map[string]string{strings.Repeat("a", 128): "value", strings.Repeat("b", 128): "value"},
+			expectedToCreate: map[string]string{
+				strings.Repeat("b", 128):        "value",
+				constants.ManagedKeysTag + "-1": strings.Repeat("b", 128),
+			},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "keys no longer overflowing",
+			existingTags: map[string]string{
+				strings.Repeat("a", 128):        "value",
+				strings.Repeat("b", 128):        "value",
+				constants.ManagedKeysTag:        strings.Repeat("a", 128),
+				constants.ManagedKeysTag + "-1": strings.Repeat("b", 128),
+			},
+			desiredTags:                     map[string]string{strings.Repeat("b", 128): "value"},
+			expectedToCreate:                map[string]string{constants.ManagedKeysTag: strings.Repeat("b", 128)},
+			expectedToDelete:                []string{strings.Repeat("a", 128)},
+			expectedToDeleteManagedKeysTags: []string{constants.ManagedKeysTag + "-1"},
+		},
+		{
+			testName:         "no desired tags on untagged resource",
+			existingTags:     map[string]string{"other": "value"},
+			desiredTags:      map[string]string{},
+			expectedToCreate: map[string]string{},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "conflict overwritten",
+			existingTags: map[string]string{
+				"tag1": "terraform",
+			},
+			desiredTags:      map[string]string{"tag1": "value1"},
+			conflictPolicies: tagging.ConflictPolicies{"tag1": tagging.ConflictPolicyOverwrite},
+			expectedToCreate: map[string]string{
+				"tag1": "value1",
+			},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "conflict kept",
+			existingTags: map[string]string{
+				"tag1": "terraform",
+			},
+			desiredTags:      map[string]string{"tag1": "value1", "tag2": "value2"},
+			conflictPolicies: tagging.ConflictPolicies{"tag1": tagging.ConflictPolicyKeepExisting},
+			expectedToCreate: map[string]string{
+				"tag2":                   "value2",
+				constants.ManagedKeysTag: "tag2",
+			},
+			expectedToDelete: []string{},
+		},
+		{
+			testName: "managed tag is not a conflict",
+			existingTags: map[string]string{
+				"tag1":                   "old",
+				constants.ManagedKeysTag: "tag1",
+			},
+			desiredTags:      map[string]string{"tag1": "new"},
+			conflictPolicies: tagging.ConflictPolicies{"tag1": tagging.ConflictPolicyFail},
+			expectedToCreate: map[string]string{"tag1": "new"},
+			expectedToDelete: []string{},
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			changes, err := planTagChanges(newAwsTags(testData.existingTags), constants.ManagedKeysTag,
+				testData.desiredTags, testData.conflictPolicies)
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedToCreate, changes.toCreate)
+			assert.Equal(t, testData.expectedToDelete, changes.toDelete)
+
+			expectedToDeleteManagedKeysTags := testData.expectedToDeleteManagedKeysTags
+			if expectedToDeleteManagedKeysTags == nil {
+				expectedToDeleteManagedKeysTags = []string{}
+			}
+
+			assert.Equal(t, expectedToDeleteManagedKeysTags, changes.toDeleteManagedKeysTags)
+
+			// Once the changes are made the resource is tagged
+			tagged, err := planTagChanges(changes.applyTo(newAwsTags(testData.existingTags)), constants.ManagedKeysTag,
+				testData.desiredTags, testData.conflictPolicies)
+
+			assert.NoError(t, err)
+			assert.True(t, tagged.empty())
+		})
+	}
+}
+
+func TestPlanTagChanges_KeepsExistingTagWithTheDesiredValue_When_NoLongerDesired(t *testing.T) {
+	existingTags := newAwsTags(map[string]string{"team": "platform"})
+	desiredTags := map[string]string{"team": "platform", "env": "prod"}
+
+	changes, err := planTagChanges(existingTags, constants.ManagedKeysTag, desiredTags, nil)
+	assert.NoError(t, err)
+
+	tagged := changes.applyTo(existingTags)
+
+	changes, err = planTagChanges(tagged, constants.ManagedKeysTag, map[string]string{"env": "prod"}, nil)
+	assert.NoError(t, err)
+
+	// Only the tag node-tagger created is recorded, so the team tag it found is never removed
+	assert.Equal(t, []string{}, changes.toDelete)
+	assert.True(t, changes.empty())
+
+	changes, err = planTagChanges(tagged, constants.ManagedKeysTag, map[string]string{}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"env"}, changes.toDelete)
+	assert.Equal(t, []string{constants.ManagedKeysTag}, changes.toDeleteManagedKeysTags)
+}
+
+func TestPlanTagChanges_KeepsTheKeysOfEveryManagedKeysTagApart(t *testing.T) {
+	// The volume is tagged from both its node and its claim, each owning its own tags
+	existingTags := newAwsTags(map[string]string{
+		"node":                         "value",
+		"claim":                        "value",
+		constants.ManagedKeysTag:       "node",
+		constants.VolumeManagedKeysTag: "claim",
+	})
+
+	changes, err := planTagChanges(existingTags, constants.VolumeManagedKeysTag, map[string]string{}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"claim"}, changes.toDelete)
+	assert.Equal(t, []string{constants.VolumeManagedKeysTag}, changes.toDeleteManagedKeysTags)
+
+	changes, err = planTagChanges(existingTags, constants.ManagedKeysTag, map[string]string{"node": "value"}, nil)
+
+	assert.NoError(t, err)
+	assert.True(t, changes.empty())
+}
+
+func TestPlanTagChanges_LeavesTheTagsOfTheOtherSourceAlone_When_BothTagAVolume(t *testing.T) {
+	// The volume is tagged from its node with --tag-data-volumes and from its claim, with a different team for each
+	nodeTags := map[string]string{"team": "platform", "node": "value"}
+	claimTags := map[string]string{"team": "data", "claim": "value"}
+
+	tagged := newAwsTags(map[string]string{})
+
+	for i := 0; i < 3; i++ {
+		for _, source := range []struct {
+			managedKeysTag string
+			tags           map[string]string
+		}{
+			{managedKeysTag: constants.ManagedKeysTag, tags: nodeTags},
+			{managedKeysTag: constants.VolumeManagedKeysTag, tags: claimTags},
+		} {
+			changes, err := planTagChanges(tagged, source.managedKeysTag, source.tags, nil)
+			assert.NoError(t, err)
+
+			// The volume is tagged by the first reconcile of each source, and neither overwrites the other after
+			if i > 0 {
+				assert.True(t, changes.empty(), "%s changed the volume on round %d", source.managedKeysTag, i)
+			}
+
+			tagged = changes.applyTo(tagged)
+		}
+	}
+
+	existing := map[string]string{}
+	for _, tag := range tagged {
+		existing[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	// The first source to tag the volume keeps the shared key
+	assert.Equal(t, map[string]string{
+		"team":                         "platform",
+		"node":                         "value",
+		"claim":                        "value",
+		constants.ManagedKeysTag:       "node,team",
+		constants.VolumeManagedKeysTag: "claim",
+	}, existing)
+}
+
+func TestPlanTagChanges_KeepsAKeyRecordedBySeveralSourcesForTheFirstOne(t *testing.T) {
+	existingTags := newAwsTags(map[string]string{
+		"team":                         "platform",
+		constants.ManagedKeysTag:       "team",
+		constants.VolumeManagedKeysTag: "team",
+	})
+
+	// The claim gives the key up without deleting it
+	changes, err := planTagChanges(existingTags, constants.VolumeManagedKeysTag, map[string]string{"team": "data"},
+		nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{}, changes.toCreate)
+	assert.Equal(t, []string{}, changes.toDelete)
+	assert.Equal(t, []string{constants.VolumeManagedKeysTag}, changes.toDeleteManagedKeysTags)
+
+	// The node keeps it
+	changes, err = planTagChanges(existingTags, constants.ManagedKeysTag, map[string]string{"team": "ml"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "ml"}, changes.toCreate)
+}
+
+func TestPlanTagChanges_RecordsEveryKey_If_KeysOverflowTheManagedKeysTag(t *testing.T) {
+	desiredTags := map[string]string{}
+	for i := 0; i < 20; i++ {
+		desiredTags[fmt.Sprintf("%02d%s", i, strings.Repeat("k", 28))] = "value"
+	}
+
+	changes, err := planTagChanges([]*ec2.Tag{}, constants.ManagedKeysTag, desiredTags, nil)
+	assert.NoError(t, err)
+
+	// 8 keys of 30 characters fit in a value of 256 characters along with the separators
+	tagged := changes.applyTo([]*ec2.Tag{})
+	assert.Len(t, tagged, 23)
+
+	existing := map[string]string{}
+	for _, tag := range tagged {
+		existing[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	assert.Equal(t, sortedKeys(desiredTags), tagging.ParseManagedKeys(constants.ManagedKeysTag, existing))
+	// The managed keys tags are left out of the keys reported as set
+	assert.Equal(t, sortedKeys(desiredTags), changes.result().SetKeys)
+}
+
+func TestPlanTagChanges_ReturnsConflictError_If_ConflictPolicyIsFail(t *testing.T) {
+	existingTags := map[string]string{
+		"tag1": "terraform1",
+		"tag2": "terraform2",
+		"tag3": "terraform3",
+	}
+	desiredTags := map[string]string{
+		"tag1": "value1",
+		"tag2": "value2",
+		"tag3": "value3",
+	}
+	conflictPolicies := tagging.ConflictPolicies{
+		"tag1": tagging.ConflictPolicyFail,
+		"tag2": tagging.ConflictPolicyKeepExisting,
+		"tag3": tagging.ConflictPolicyFail,
+	}
+
+	_, err := planTagChanges(newAwsTags(existingTags), constants.ManagedKeysTag, desiredTags, conflictPolicies)
+
+	assert.Equal(t, &tagging.ConflictError{
+		Conflicts: []tagging.Conflict{
+			{Key: "tag1", ExistingValue: "terraform1", DesiredValue: "value1"},
+			{Key: "tag3", ExistingValue: "terraform3", DesiredValue: "value3"},
+		},
+	}, err)
+}
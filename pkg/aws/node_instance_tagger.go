@@ -1,11 +1,16 @@
 package aws
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/go-logr/logr"
 	"github.com/ouzi-dev/node-tagger/pkg/constants"
-	"github.com/pkg/errors"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
 	corev1 "k8s.io/api/core/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -13,85 +18,268 @@ import (
 //nolint
 //go:generate mockgen -package=mocks -destination ../mocks/mock_ec2iface.go github.com/aws/aws-sdk-go/service/ec2/ec2iface EC2API
 
+// NodeInstanceTaggerOptions selects the resources attached to a node instance that are tagged along with it, and how
+// the calls to the EC2 API are made
+type NodeInstanceTaggerOptions struct {
+	// TagRootVolumes tags the EBS root volume of the instance
+	TagRootVolumes bool
+	// TagDataVolumes tags the EBS volumes attached to the instance other than the root one
+	TagDataVolumes bool
+	// TagNetworkInterfaces tags the network interfaces attached to the instance, including the secondary ones
+	TagNetworkInterfaces bool
+	// BatchWindow gathers the instance lookups and the tag writes of the nodes reconciled within it into batches. It
+	// is only used by the regional NodeTagger, and a zero window disables batching
+	BatchWindow time.Duration
+	// TagCacheTTL is how long the tags of an instance are trusted without reading them from the EC2 API again. A zero
+	// TTL disables the cache, and so does tagging any resource attached to the instance
+	TagCacheTTL time.Duration
+	// DefaultRegion is the region of the nodes whose region can be found neither in their ProviderID nor in their
+	// labels. It is only used by the regional NodeTagger, and an empty region leaves those nodes untagged
+	DefaultRegion string
+}
+
 type nodeInstanceTagger struct {
 	ec2Client ec2iface.EC2API
+	options   NodeInstanceTaggerOptions
+	tagCache  *tagCache
 }
 
 var log = logf.Log.WithName("node_instance_tagger")
 
-func NewNodeInstanceTagger(ec2Client ec2iface.EC2API) NodeTagger {
+func NewNodeInstanceTagger(ec2Client ec2iface.EC2API, options NodeInstanceTaggerOptions) provider.NodeTagger {
+	tagCacheTTL := options.TagCacheTTL
+
+	// The volumes and network interfaces attached since the last write are only found by describing the instance
+	if options.TagRootVolumes || options.TagDataVolumes || options.TagNetworkInterfaces {
+		tagCacheTTL = 0
+	}
+
 	return &nodeInstanceTagger{
 		ec2Client: ec2Client,
+		options:   options,
+		tagCache:  newTagCache(tagCacheTTL),
 	}
 }
 
-func (n *nodeInstanceTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string) error {
-	log.WithValues("Node.Name", node.Name)
+func (n *nodeInstanceTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	result, err := n.ensureInstanceNodeHasTags(node, tags, conflictPolicies)
+
+	return result, wrapPermissionDenied(err)
+}
 
-	describeInstancesInput := &ec2.DescribeInstancesInput{
-		Filters: []*ec2.Filter{
-			{
-				Name:   aws.String("private-dns-name"),
-				Values: []*string{aws.String(node.Name)},
-			},
-		},
+func (n *nodeInstanceTagger) ensureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	nodeLogger := log.WithValues("Node.Name", node.Name)
+	resyncToken := node.Annotations[constants.ResyncAnnotation]
+
+	if n.isKnownToBeTagged(nodeLogger, node, resyncToken, tags, conflictPolicies) {
+		return provider.TagResult{}, nil
 	}
 
-	describeInstancesOutput, err := n.ec2Client.DescribeInstances(describeInstancesInput)
+	instance, err := findInstance(n.ec2Client, nodeLogger, node)
 	if err != nil {
-		return err
+		return provider.TagResult{}, err
 	}
 
-	if len(describeInstancesOutput.Reservations) == 0 || len(describeInstancesOutput.Reservations[0].Instances) == 0 {
-		return errors.Errorf("No instances found for the node with private dns: %s", node.Name)
+	instanceLogger := nodeLogger.WithValues("Instance.ID", *instance.InstanceId)
+
+	changes, err := planTagChanges(instance.Tags, constants.ManagedKeysTag, tags, conflictPolicies)
+	if err != nil {
+		return provider.TagResult{}, err
 	}
 
-	if len(describeInstancesOutput.Reservations) > 1 || len(describeInstancesOutput.Reservations[0].Instances) > 1 {
-		return errors.Errorf("More than one instances found with private dns: %s. Cannot proceed with tagging",
-			node.Name)
+	err = writeTagChanges(&ec2TagWriter{ec2Client: n.ec2Client, resourceID: instance.InstanceId}, instanceLogger,
+		changes)
+	if err != nil {
+		return provider.TagResult{}, err
 	}
 
-	existingTags := describeInstancesOutput.Reservations[0].Instances[0].Tags
-	instanceID := describeInstancesOutput.Reservations[0].Instances[0].InstanceId
+	result := changes.result()
 
-	if instanceAlreadyTagged(tags, existingTags) {
-		log.V(constants.DebugLogVerbosity).Info("Instance already tagged.", "Instance.ID", *instanceID)
-		return nil
+	volumesResult, err := n.ensureVolumesHaveTags(nodeLogger, instance, tags, conflictPolicies)
+	if err != nil {
+		return provider.TagResult{}, err
 	}
 
-	newTags := convertDesiredTagsToAwsTags(tags)
+	result.Add(volumesResult)
 
-	createTagsInput := &ec2.CreateTagsInput{
-		Resources: []*string{instanceID},
-		Tags:      newTags,
+	networkInterfacesResult, err := n.ensureNetworkInterfacesHaveTags(nodeLogger, instance, tags, conflictPolicies)
+	if err != nil {
+		return provider.TagResult{}, err
 	}
 
-	log.Info("Tagging instance.", "Instance.ID", *instanceID)
+	result.Add(networkInterfacesResult)
+
+	n.tagCache.set(*instance.InstanceId, resyncToken, changes.applyTo(instance.Tags))
+
+	return result, nil
+}
+
+// Resync forgets the tags of every instance, so they are read from the EC2 API again
+func (n *nodeInstanceTagger) Resync() {
+	n.tagCache.clear()
+}
+
+// isKnownToBeTagged tells whether the instance of the node had the desired tags when node-tagger last saw them, so
+// reconciling the node does not need any call to the EC2 API
+func (n *nodeInstanceTagger) isKnownToBeTagged(nodeLogger logr.Logger, node *corev1.Node, resyncToken string,
+	tags map[string]string, conflictPolicies tagging.ConflictPolicies) bool {
+	if !n.tagCache.enabled() {
+		return false
+	}
 
-	_, err = n.ec2Client.CreateTags(createTagsInput)
+	_, instanceID, err := parseProviderID(node.Spec.ProviderID)
 	if err != nil {
-		return err
+		return false
+	}
+
+	cachedTags, found := n.tagCache.get(instanceID, resyncToken)
+	if found {
+		// A conflict is not trusted to the cache, so its error comes from the current tags of the instance
+		changes, err := planTagChanges(cachedTags, constants.ManagedKeysTag, tags, conflictPolicies)
+		found = err == nil && changes.empty()
+	}
+
+	if !found {
+		metrics.TagCacheRequests.WithLabelValues("miss").Inc()
+		return false
 	}
 
-	return nil
+	metrics.TagCacheRequests.WithLabelValues("hit").Inc()
+	nodeLogger.V(constants.DebugLogVerbosity).Info("Instance known to be tagged.", "Instance.ID", instanceID)
+
+	return true
 }
 
-func instanceAlreadyTagged(requestedTags map[string]string, existingTags []*ec2.Tag) bool {
-	for requestedTagKey, requestedTagValue := range requestedTags {
-		existingKeyFound := false
+// ensureVolumesHaveTags tags the EBS volumes of the instance selected by the options. The volumes are listed from
+// the instance on every call, so volumes attached since the last one are picked up.
+func (n *nodeInstanceTagger) ensureVolumesHaveTags(nodeLogger logr.Logger, instance *ec2.Instance,
+	tags map[string]string, conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	result := provider.TagResult{}
+	volumeIDs := []*string{}
 
-		for _, existingTag := range existingTags {
-			if *existingTag.Key == requestedTagKey && *existingTag.Value == requestedTagValue {
-				existingKeyFound = true
-			}
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.Ebs == nil || mapping.Ebs.VolumeId == nil {
+			continue
 		}
 
-		if !existingKeyFound {
-			return false
+		isRootVolume := aws.StringValue(mapping.DeviceName) == aws.StringValue(instance.RootDeviceName)
+
+		if (isRootVolume && n.options.TagRootVolumes) || (!isRootVolume && n.options.TagDataVolumes) {
+			volumeIDs = append(volumeIDs, mapping.Ebs.VolumeId)
 		}
 	}
 
-	return true
+	if len(volumeIDs) == 0 {
+		return result, nil
+	}
+
+	describeVolumesOutput, err := n.ec2Client.DescribeVolumes(&ec2.DescribeVolumesInput{
+		VolumeIds: volumeIDs,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, volume := range describeVolumesOutput.Volumes {
+		volumeResult, err := ensureResourceHasTags(n.ec2Client, nodeLogger.WithValues("Volume.ID", *volume.VolumeId),
+			volume.VolumeId, volume.Tags, constants.ManagedKeysTag, tags, conflictPolicies)
+		if err != nil {
+			return result, err
+		}
+
+		result.Add(volumeResult)
+	}
+
+	return result, nil
+}
+
+// ensureNetworkInterfacesHaveTags tags the network interfaces attached to the instance when enabled by the options.
+// Each interface is checked on its own, so only the ones missing tags are written to.
+func (n *nodeInstanceTagger) ensureNetworkInterfacesHaveTags(nodeLogger logr.Logger, instance *ec2.Instance,
+	tags map[string]string, conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	result := provider.TagResult{}
+
+	if !n.options.TagNetworkInterfaces {
+		return result, nil
+	}
+
+	networkInterfaceIDs := []*string{}
+
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface.NetworkInterfaceId != nil {
+			networkInterfaceIDs = append(networkInterfaceIDs, networkInterface.NetworkInterfaceId)
+		}
+	}
+
+	if len(networkInterfaceIDs) == 0 {
+		return result, nil
+	}
+
+	describeNetworkInterfacesOutput, err := n.ec2Client.DescribeNetworkInterfaces(
+		&ec2.DescribeNetworkInterfacesInput{
+			NetworkInterfaceIds: networkInterfaceIDs,
+		})
+	if err != nil {
+		return result, err
+	}
+
+	for _, networkInterface := range describeNetworkInterfacesOutput.NetworkInterfaces {
+		networkInterfaceResult, err := ensureResourceHasTags(n.ec2Client,
+			nodeLogger.WithValues("NetworkInterface.ID", *networkInterface.NetworkInterfaceId),
+			networkInterface.NetworkInterfaceId, networkInterface.TagSet, constants.ManagedKeysTag, tags,
+			conflictPolicies)
+		if err != nil {
+			return result, err
+		}
+
+		result.Add(networkInterfaceResult)
+	}
+
+	return result, nil
+}
+
+// ensureResourceHasTags brings the existing tags of a single EC2 resource to the desired state, skipping any API
+// call when the resource is already tagged, and reports the keys of the tags it wrote
+func ensureResourceHasTags(ec2Client ec2iface.EC2API, resourceLogger logr.Logger, resourceID *string,
+	existingTags []*ec2.Tag, managedKeysTag string, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	changes, err := planTagChanges(existingTags, managedKeysTag, tags, conflictPolicies)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	err = writeTagChanges(&ec2TagWriter{ec2Client: ec2Client, resourceID: resourceID}, resourceLogger, changes)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	return changes.result(), nil
+}
+
+// ec2TagWriter writes the tags of a single EC2 resource
+type ec2TagWriter struct {
+	ec2Client  ec2iface.EC2API
+	resourceID *string
+}
+
+func (w *ec2TagWriter) deleteTags(keys []string) error {
+	_, err := w.ec2Client.DeleteTags(&ec2.DeleteTagsInput{
+		Resources: []*string{w.resourceID},
+		Tags:      convertKeysToAwsTags(keys),
+	})
+
+	return err
+}
+
+func (w *ec2TagWriter) createTags(tags map[string]string) error {
+	_, err := w.ec2Client.CreateTags(&ec2.CreateTagsInput{
+		Resources: []*string{w.resourceID},
+		Tags:      convertDesiredTagsToAwsTags(tags),
+	})
+
+	return err
 }
 
 func convertDesiredTagsToAwsTags(requestedTags map[string]string) []*ec2.Tag {
@@ -107,3 +295,14 @@ func convertDesiredTagsToAwsTags(requestedTags map[string]string) []*ec2.Tag {
 
 	return resultTags
 }
+
+// convertKeysToAwsTags converts tag keys to tags without a value, which delete the tags regardless of their value
+func convertKeysToAwsTags(keys []string) []*ec2.Tag {
+	resultTags := []*ec2.Tag{}
+
+	for _, tagKey := range keys {
+		resultTags = append(resultTags, &ec2.Tag{Key: aws.String(tagKey)})
+	}
+
+	return resultTags
+}
@@ -6,9 +6,12 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
 	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -49,9 +52,9 @@ func CreateTagsInputMatcher(input *ec2.CreateTagsInput) gomock.Matcher {
 // Compares the resource and tags if the input
 func (e createTagsInputMatcher) Matches(x interface{}) bool {
 	resourceMatches := *x.(*ec2.CreateTagsInput).Resources[0] == *e.x.Resources[0]
-	tagsMatch := true
 	tags := x.(*ec2.CreateTagsInput).Tags
 	tagsToMatch := e.x.Tags
+	tagsMatch := len(tags) == len(tagsToMatch)
 
 	for _, tag := range tags {
 		tagMatches := false
@@ -81,7 +84,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Err
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -89,6 +92,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Err
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -98,18 +105,39 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Err
 		Return(nil, errGeneric).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.EqualError(t, err, errGeneric.Error())
 }
 
+func TestEnsureInstanceNodeHasTags_ReturnsPermissionDeniedError_If_DescribeInstances_Is_Unauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
+
+	unauthorized := awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(gomock.Any()).
+		Return(nil, unauthorized).
+		Times(Once)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.Equal(t, &provider.PermissionDeniedError{Err: unauthorized}, err)
+}
+
 func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_No_Matches(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -117,6 +145,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_No_
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -135,9 +167,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_No_
 		Return(&describeInstancesOutput, nil).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.EqualError(t, err, noInstancesFoundError)
+	assert.IsType(t, &InstanceNotFoundError{}, err)
 }
 
 func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Multiple_Matches(t *testing.T) {
@@ -146,7 +179,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Mul
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -154,6 +187,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Mul
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -181,9 +218,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_DescribeInstances_Returns_Mul
 		Return(&describeInstancesOutput, nil).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.EqualError(t, err, multipleInstancesFoundError)
+	assert.IsType(t, &MultipleInstancesError{}, err)
 }
 
 func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyTagged(t *testing.T) {
@@ -192,7 +230,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyTagged(t *te
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -200,6 +238,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyTagged(t *te
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -220,6 +262,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyTagged(t *te
 								Key:   aws.String("tag2"),
 								Value: aws.String("value2"),
 							},
+							{
+								Key:   aws.String(constants.ManagedKeysTag),
+								Value: aws.String("tag1,tag2"),
+							},
 						},
 					},
 				},
@@ -233,9 +279,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyTagged(t *te
 		Return(&describeInstancesOutput, nil).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.NoError(t, err, multipleInstancesFoundError)
+	assert.False(t, result.Changed())
 }
 
 func TestEnsureInstanceNodeHasTags_ReturnsError_If_InstanceFailsTagging(t *testing.T) {
@@ -244,7 +291,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_InstanceFailsTagging(t *testi
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -252,6 +299,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_InstanceFailsTagging(t *testi
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -281,6 +332,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_InstanceFailsTagging(t *testi
 				Key:   aws.String("tag2"),
 				Value: aws.String("value2"),
 			},
+			{
+				Key:   aws.String(constants.ManagedKeysTag),
+				Value: aws.String("tag1,tag2"),
+			},
 		},
 	}
 
@@ -296,7 +351,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsError_If_InstanceFailsTagging(t *testi
 		Return(nil, errGeneric).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.EqualError(t, err, errGeneric.Error())
 }
@@ -307,7 +362,7 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceSucceedsTagging(t *
 
 	mockEc2Client := mocks.NewMockEC2API(ctrl)
 
-	subject := NewNodeInstanceTagger(mockEc2Client)
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
 
 	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{
@@ -315,6 +370,10 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceSucceedsTagging(t *
 				Name:   aws.String("private-dns-name"),
 				Values: []*string{aws.String(inputNode.Name)},
 			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
 		},
 	}
 
@@ -344,6 +403,104 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceSucceedsTagging(t *
 				Key:   aws.String("tag2"),
 				Value: aws.String("value2"),
 			},
+			{
+				Key:   aws.String(constants.ManagedKeysTag),
+				Value: aws.String("tag1,tag2"),
+			},
+		},
+	}
+
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(&expectedDescribeInstancesInput).
+		Return(&describeInstancesOutput, nil).
+		Times(Once)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&expectedCreateTagsInput)).
+		Return(nil, nil).
+		Times(Once)
+
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"tag1", "tag2"}, nil), result)
+}
+
+func TestEnsureInstanceNodeHasTags_RemovesManagedTagsNoLongerDesired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{})
+
+	expectedDescribeInstancesInput := ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("private-dns-name"),
+				Values: []*string{aws.String(inputNode.Name)},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
+		},
+	}
+
+	describeInstancesOutput := ec2.DescribeInstancesOutput{
+		NextToken: nil,
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId:     aws.String(instanceID),
+						PrivateDnsName: aws.String(nodeName),
+						Tags: []*ec2.Tag{
+							{
+								Key:   aws.String("tag1"),
+								Value: aws.String("value1"),
+							},
+							{
+								Key:   aws.String("tag2"),
+								Value: aws.String("value2"),
+							},
+							{
+								Key:   aws.String("tag3"),
+								Value: aws.String("value3"),
+							},
+							{
+								Key:   aws.String("tag3-unmanaged"),
+								Value: aws.String("value3"),
+							},
+							{
+								Key:   aws.String(constants.ManagedKeysTag),
+								Value: aws.String("tag1,tag2,tag3"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedDeleteTagsInput := ec2.DeleteTagsInput{
+		Resources: []*string{aws.String(instanceID)},
+		Tags: []*ec2.Tag{
+			{
+				Key: aws.String("tag3"),
+			},
+		},
+	}
+
+	expectedCreateTagsInput := ec2.CreateTagsInput{
+		Resources: []*string{aws.String(instanceID)},
+		Tags: []*ec2.Tag{
+			{
+				Key:   aws.String(constants.ManagedKeysTag),
+				Value: aws.String("tag1,tag2"),
+			},
 		},
 	}
 
@@ -353,13 +510,240 @@ func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceSucceedsTagging(t *
 		Return(&describeInstancesOutput, nil).
 		Times(Once)
 
+	deleteTags := mockEc2Client.
+		EXPECT().
+		DeleteTags(&expectedDeleteTagsInput).
+		Return(nil, nil).
+		Times(Once)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&expectedCreateTagsInput)).
+		Return(nil, nil).
+		Times(Once).
+		After(deleteTags)
+
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult(nil, []string{"tag3"}), result)
+}
+
+func TestEnsureInstanceNodeHasTags_TagsSelectedVolumes(t *testing.T) {
+	tests := []struct {
+		testName          string
+		options           NodeInstanceTaggerOptions
+		expectedVolumeIDs []string
+	}{
+		{
+			testName:          "root volumes",
+			options:           NodeInstanceTaggerOptions{TagRootVolumes: true},
+			expectedVolumeIDs: []string{"vol-root"},
+		},
+		{
+			testName:          "data volumes",
+			options:           NodeInstanceTaggerOptions{TagDataVolumes: true},
+			expectedVolumeIDs: []string{"vol-data1", "vol-data2"},
+		},
+		{
+			testName:          "all volumes",
+			options:           NodeInstanceTaggerOptions{TagRootVolumes: true, TagDataVolumes: true},
+			expectedVolumeIDs: []string{"vol-root", "vol-data1", "vol-data2"},
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+			subject := NewNodeInstanceTagger(mockEc2Client, testData.options)
+
+			describeInstancesOutput := ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{
+						Instances: []*ec2.Instance{
+							{
+								InstanceId:     aws.String(instanceID),
+								PrivateDnsName: aws.String(nodeName),
+								RootDeviceName: aws.String("/dev/xvda"),
+								BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{
+									{
+										DeviceName: aws.String("/dev/xvda"),
+										Ebs:        &ec2.EbsInstanceBlockDevice{VolumeId: aws.String("vol-root")},
+									},
+									{
+										DeviceName: aws.String("/dev/xvdb"),
+										Ebs:        &ec2.EbsInstanceBlockDevice{VolumeId: aws.String("vol-data1")},
+									},
+									{
+										DeviceName: aws.String("/dev/xvdc"),
+										Ebs:        &ec2.EbsInstanceBlockDevice{VolumeId: aws.String("vol-data2")},
+									},
+								},
+								Tags: []*ec2.Tag{
+									{
+										Key:   aws.String("tag1"),
+										Value: aws.String("value1"),
+									},
+									{
+										Key:   aws.String("tag2"),
+										Value: aws.String("value2"),
+									},
+									{
+										Key:   aws.String(constants.ManagedKeysTag),
+										Value: aws.String("tag1,tag2"),
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			expectedDescribeVolumesInput := ec2.DescribeVolumesInput{
+				VolumeIds: aws.StringSlice(testData.expectedVolumeIDs),
+			}
+
+			describeVolumesOutput := ec2.DescribeVolumesOutput{}
+			for _, volumeID := range testData.expectedVolumeIDs {
+				describeVolumesOutput.Volumes = append(describeVolumesOutput.Volumes, &ec2.Volume{
+					VolumeId: aws.String(volumeID),
+					Tags:     []*ec2.Tag{},
+				})
+			}
+
+			mockEc2Client.
+				EXPECT().
+				DescribeInstances(gomock.Any()).
+				Return(&describeInstancesOutput, nil).
+				Times(Once)
+
+			mockEc2Client.
+				EXPECT().
+				DescribeVolumes(&expectedDescribeVolumesInput).
+				Return(&describeVolumesOutput, nil).
+				Times(Once)
+
+			for _, volumeID := range testData.expectedVolumeIDs {
+				expectedCreateTagsInput := ec2.CreateTagsInput{
+					Resources: []*string{aws.String(volumeID)},
+					Tags: []*ec2.Tag{
+						{
+							Key:   aws.String("tag1"),
+							Value: aws.String("value1"),
+						},
+						{
+							Key:   aws.String("tag2"),
+							Value: aws.String("value2"),
+						},
+						{
+							Key:   aws.String(constants.ManagedKeysTag),
+							Value: aws.String("tag1,tag2"),
+						},
+					},
+				}
+
+				mockEc2Client.
+					EXPECT().
+					CreateTags(CreateTagsInputMatcher(&expectedCreateTagsInput)).
+					Return(nil, nil).
+					Times(Once)
+			}
+
+			_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEnsureInstanceNodeHasTags_TagsOnlyUntaggedNetworkInterfaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{TagNetworkInterfaces: true})
+
+	desiredAwsTags := []*ec2.Tag{
+		{
+			Key:   aws.String("tag1"),
+			Value: aws.String("value1"),
+		},
+		{
+			Key:   aws.String("tag2"),
+			Value: aws.String("value2"),
+		},
+		{
+			Key:   aws.String(constants.ManagedKeysTag),
+			Value: aws.String("tag1,tag2"),
+		},
+	}
+
+	describeInstancesOutput := ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId:     aws.String(instanceID),
+						PrivateDnsName: aws.String(nodeName),
+						NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+							{NetworkInterfaceId: aws.String("eni-primary")},
+							{NetworkInterfaceId: aws.String("eni-secondary")},
+						},
+						Tags: desiredAwsTags,
+					},
+				},
+			},
+		},
+	}
+
+	expectedDescribeNetworkInterfacesInput := ec2.DescribeNetworkInterfacesInput{
+		NetworkInterfaceIds: aws.StringSlice([]string{"eni-primary", "eni-secondary"}),
+	}
+
+	describeNetworkInterfacesOutput := ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-primary"),
+				TagSet:             desiredAwsTags,
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-secondary"),
+				TagSet:             []*ec2.Tag{},
+			},
+		},
+	}
+
+	expectedCreateTagsInput := ec2.CreateTagsInput{
+		Resources: []*string{aws.String("eni-secondary")},
+		Tags:      desiredAwsTags,
+	}
+
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(gomock.Any()).
+		Return(&describeInstancesOutput, nil).
+		Times(Once)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeNetworkInterfaces(&expectedDescribeNetworkInterfacesInput).
+		Return(&describeNetworkInterfacesOutput, nil).
+		Times(Once)
+
 	mockEc2Client.
 		EXPECT().
 		CreateTags(CreateTagsInputMatcher(&expectedCreateTagsInput)).
 		Return(nil, nil).
 		Times(Once)
 
-	err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags)
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
 
 	assert.NoError(t, err)
 }
@@ -0,0 +1,25 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/pkg/errors"
+)
+
+// permissionDeniedCodes are the error codes the AWS APIs return for calls the credentials are not allowed to make
+var permissionDeniedCodes = map[string]bool{
+	"UnauthorizedOperation": true,
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+}
+
+// wrapPermissionDenied marks the errors of the calls the credentials are not allowed to make, so they are told apart
+// from the other failures
+func wrapPermissionDenied(err error) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && permissionDeniedCodes[awsErr.Code()] {
+		return &provider.PermissionDeniedError{Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// zoneRegionRegex matches the region at the start of an availability zone, e.g. eu-west-1 in eu-west-1a or us-west-2
+// in the local zone us-west-2-lax-1a
+var zoneRegionRegex = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+`)
+
+// RegionError is returned for nodes whose region can neither be found in their ProviderID nor in their labels, when
+// there is no default region either
+type RegionError struct {
+	Node string
+}
+
+func (e *RegionError) Error() string {
+	return fmt.Sprintf("cannot determine the region of node %s from its ProviderID or its %s label, and no "+
+		"default region is configured",
+		e.Node, corev1.LabelZoneRegionStable)
+}
+
+// nodeRegion returns the region of the instance of the node. It is derived from the availability zone in the ProviderID
+// of the node, or read from the region labels set by the cloud provider when the ProviderID has no zone. The default
+// region is the last resort.
+func nodeRegion(node *corev1.Node, defaultRegion string) (string, error) {
+	if zone, _, err := parseProviderID(node.Spec.ProviderID); err == nil && zone != "" {
+		if region := zoneRegionRegex.FindString(zone); region != "" {
+			return region, nil
+		}
+	}
+
+	for _, label := range []string{corev1.LabelZoneRegionStable, corev1.LabelZoneRegion} {
+		if region := node.Labels[label]; region != "" {
+			return region, nil
+		}
+	}
+
+	if defaultRegion != "" {
+		return defaultRegion, nil
+	}
+
+	return "", &RegionError{Node: node.Name}
+}
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeRegion(t *testing.T) {
+	testCases := []struct {
+		name           string
+		providerID     string
+		labels         map[string]string
+		defaultRegion  string
+		expectedRegion string
+		expectedError  string
+	}{
+		{
+			name:           "region from the availability zone",
+			providerID:     "aws:///eu-west-1a/i-0123456789abcdef0",
+			labels:         map[string]string{corev1.LabelZoneRegionStable: "us-east-1"},
+			expectedRegion: "eu-west-1",
+		},
+		{
+			name:           "region from a local zone",
+			providerID:     "aws:///us-west-2-lax-1a/i-0123456789abcdef0",
+			expectedRegion: "us-west-2",
+		},
+		{
+			name:           "region from a GovCloud zone",
+			providerID:     "aws:///us-gov-west-1b/i-0123456789abcdef0",
+			expectedRegion: "us-gov-west-1",
+		},
+		{
+			name:           "region from the topology label",
+			providerID:     "aws:///i-0123456789abcdef0",
+			labels:         map[string]string{corev1.LabelZoneRegionStable: "eu-central-1"},
+			expectedRegion: "eu-central-1",
+		},
+		{
+			name:           "region from the beta label",
+			providerID:     "aws:///i-0123456789abcdef0",
+			labels:         map[string]string{corev1.LabelZoneRegion: "ap-southeast-2"},
+			expectedRegion: "ap-southeast-2",
+		},
+		{
+			name:           "labels before the default region",
+			providerID:     "aws:///i-0123456789abcdef0",
+			labels:         map[string]string{corev1.LabelZoneRegionStable: "eu-central-1"},
+			defaultRegion:  "eu-west-2",
+			expectedRegion: "eu-central-1",
+		},
+		{
+			name:           "default region",
+			providerID:     "aws:///i-0123456789abcdef0",
+			defaultRegion:  "eu-west-2",
+			expectedRegion: "eu-west-2",
+		},
+		{
+			name:       "unknown region",
+			providerID: "aws:///i-0123456789abcdef0",
+			expectedError: "cannot determine the region of node Node from its ProviderID or its " +
+				"topology.kubernetes.io/region label, and no default region is configured",
+		},
+	}
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "Node",
+					Labels: testData.labels,
+				},
+				Spec: corev1.NodeSpec{
+					ProviderID: testData.providerID,
+				},
+			}
+
+			region, err := nodeRegion(node, testData.defaultRegion)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedRegion, region)
+		})
+	}
+}
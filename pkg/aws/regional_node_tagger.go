@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EC2ClientFactory creates an EC2 client for the region
+type EC2ClientFactory func(region string) (ec2iface.EC2API, error)
+
+// regionalNodeTagger tags every node through the EC2 API of the region of its instance, so the nodes of a cluster can
+// span regions. The client of a region is created the first time a node of the region is tagged.
+type regionalNodeTagger struct {
+	newEC2Client EC2ClientFactory
+	options      NodeInstanceTaggerOptions
+
+	lock    sync.Mutex
+	taggers map[string]provider.NodeTagger
+}
+
+func NewRegionalNodeTagger(newEC2Client EC2ClientFactory, options NodeInstanceTaggerOptions) provider.NodeTagger {
+	return &regionalNodeTagger{
+		newEC2Client: NewBatchingEC2ClientFactory(newEC2Client, options.BatchWindow),
+		options:      options,
+		taggers:      map[string]provider.NodeTagger{},
+	}
+}
+
+func (r *regionalNodeTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	region, err := nodeRegion(node, r.options.DefaultRegion)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	nodeTagger, err := r.regionTagger(region)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	return nodeTagger.EnsureInstanceNodeHasTags(node, tags, conflictPolicies)
+}
+
+// Resync makes the tagger of every region read the tags of its instances again
+func (r *regionalNodeTagger) Resync() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, nodeTagger := range r.taggers {
+		if resyncer, ok := nodeTagger.(provider.Resyncer); ok {
+			resyncer.Resync()
+		}
+	}
+}
+
+func (r *regionalNodeTagger) regionTagger(region string) (provider.NodeTagger, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if nodeTagger, found := r.taggers[region]; found {
+		return nodeTagger, nil
+	}
+
+	ec2Client, err := r.newEC2Client(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the EC2 client of region %s", region)
+	}
+
+	log.Info("Created EC2 client.", "Region", region)
+
+	r.taggers[region] = NewNodeInstanceTagger(ec2Client, r.options)
+
+	return r.taggers[region], nil
+}
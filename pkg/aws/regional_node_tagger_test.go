@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func regionNode(name string, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///" + zone + "/" + instanceID,
+		},
+	}
+}
+
+func TestRegionalNodeTagger_TagsNodesThroughTheClientOfTheirRegion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clients := map[string]*mocks.MockEC2API{
+		"eu-west-1": mocks.NewMockEC2API(ctrl),
+		"us-east-1": mocks.NewMockEC2API(ctrl),
+	}
+	createdClients := map[string]int{}
+
+	subject := NewRegionalNodeTagger(func(region string) (ec2iface.EC2API, error) {
+		createdClients[region]++
+		return clients[region], nil
+	}, NodeInstanceTaggerOptions{})
+
+	taggedInstance := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId: aws.String(instanceID),
+						Tags: []*ec2.Tag{
+							{Key: aws.String("tag1"), Value: aws.String("value1")},
+							{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clients["eu-west-1"].EXPECT().DescribeInstances(gomock.Any()).Return(taggedInstance, nil).Times(2)
+	clients["us-east-1"].EXPECT().DescribeInstances(gomock.Any()).Return(taggedInstance, nil).Times(Once)
+
+	tags := map[string]string{"tag1": "value1"}
+
+	for _, node := range []*corev1.Node{
+		regionNode("one", "eu-west-1a"),
+		regionNode("two", "eu-west-1b"),
+		regionNode("three", "us-east-1a"),
+	} {
+		_, err := subject.EnsureInstanceNodeHasTags(node, tags, nil)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]int{"eu-west-1": 1, "us-east-1": 1}, createdClients)
+}
+
+func TestRegionalNodeTagger_ReturnsError_If_RegionIsUnknown(t *testing.T) {
+	subject := NewRegionalNodeTagger(func(region string) (ec2iface.EC2API, error) {
+		t.Fatalf("unexpected client for region %s", region)
+		return nil, nil
+	}, NodeInstanceTaggerOptions{})
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	var regionErr *RegionError
+	assert.True(t, errors.As(err, &regionErr))
+	assert.Equal(t, nodeName, regionErr.Node)
+}
+
+func TestRegionalNodeTagger_TagsNodesInTheDefaultRegion_If_RegionIsUnknown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2Client := mocks.NewMockEC2API(ctrl)
+	createdClients := map[string]int{}
+
+	subject := NewRegionalNodeTagger(func(region string) (ec2iface.EC2API, error) {
+		createdClients[region]++
+		return ec2Client, nil
+	}, NodeInstanceTaggerOptions{DefaultRegion: "eu-west-2"})
+
+	ec2Client.EXPECT().DescribeInstances(gomock.Any()).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId: aws.String(instanceID),
+						Tags: []*ec2.Tag{
+							{Key: aws.String("tag1"), Value: aws.String("value1")},
+							{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1")},
+						},
+					},
+				},
+			},
+		},
+	}, nil).Times(Once)
+
+	node := regionNode(nodeName, "")
+	node.Spec.ProviderID = "aws:///" + instanceID
+
+	_, err := subject.EnsureInstanceNodeHasTags(node, map[string]string{"tag1": "value1"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"eu-west-2": 1}, createdClients)
+}
+
+func TestRegionalNodeTagger_ReturnsError_If_ClientCannotBeCreated(t *testing.T) {
+	subject := NewRegionalNodeTagger(func(region string) (ec2iface.EC2API, error) {
+		return nil, errGeneric
+	}, NodeInstanceTaggerOptions{})
+
+	_, err := subject.EnsureInstanceNodeHasTags(regionNode(nodeName, "eu-west-1a"), inputTags, nil)
+
+	assert.EqualError(t, err, "failed to create the EC2 client of region eu-west-1: error")
+}
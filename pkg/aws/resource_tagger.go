@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+)
+
+type ec2ResourceTagger struct {
+	ec2Client      ec2iface.EC2API
+	managedKeysTag string
+}
+
+// NewEC2ResourceTagger returns a ResourceTagger recording the keys of the tags it applies in the managedKeysTag, so
+// resources tagged from several sources can keep one for each
+func NewEC2ResourceTagger(ec2Client ec2iface.EC2API, managedKeysTag string) ResourceTagger {
+	return &ec2ResourceTagger{
+		ec2Client:      ec2Client,
+		managedKeysTag: managedKeysTag,
+	}
+}
+
+func (e *ec2ResourceTagger) EnsureResourceHasTags(resourceID string, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) error {
+	describeTagsInput := &ec2.DescribeTagsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("resource-id"),
+				Values: []*string{aws.String(resourceID)},
+			},
+		},
+	}
+
+	existingTags := []*ec2.Tag{}
+
+	err := e.ec2Client.DescribeTagsPages(describeTagsInput, func(page *ec2.DescribeTagsOutput, lastPage bool) bool {
+		for _, tag := range page.Tags {
+			existingTags = append(existingTags, &ec2.Tag{Key: tag.Key, Value: tag.Value})
+		}
+
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ensureResourceHasTags(e.ec2Client, log.WithValues("Resource.ID", resourceID), aws.String(resourceID),
+		existingTags, e.managedKeysTag, tags, conflictPolicies)
+
+	return err
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const volumeID = "vol-some-id"
+
+var expectedDescribeTagsInput = ec2.DescribeTagsInput{
+	Filters: []*ec2.Filter{
+		{
+			Name:   aws.String("resource-id"),
+			Values: []*string{aws.String(volumeID)},
+		},
+	},
+}
+
+func describeTagsPages(pages ...[]*ec2.TagDescription) func(*ec2.DescribeTagsInput,
+	func(*ec2.DescribeTagsOutput, bool) bool) error {
+	return func(input *ec2.DescribeTagsInput, fn func(*ec2.DescribeTagsOutput, bool) bool) error {
+		for i, page := range pages {
+			if !fn(&ec2.DescribeTagsOutput{Tags: page}, i == len(pages)-1) {
+				break
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestEnsureResourceHasTags_ReturnsError_If_DescribeTags_Returns_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewEC2ResourceTagger(mockEc2Client, constants.ManagedKeysTag)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeTagsPages(&expectedDescribeTagsInput, gomock.Any()).
+		Return(errGeneric).
+		Times(Once)
+
+	err := subject.EnsureResourceHasTags(volumeID, inputTags, nil)
+
+	assert.EqualError(t, err, errGeneric.Error())
+}
+
+func TestEnsureResourceHasTags_ReturnsNoError_If_ResourceAlreadyTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewEC2ResourceTagger(mockEc2Client, constants.ManagedKeysTag)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeTagsPages(&expectedDescribeTagsInput, gomock.Any()).
+		DoAndReturn(describeTagsPages(
+			[]*ec2.TagDescription{
+				{Key: aws.String("tag1"), Value: aws.String("value1"), ResourceId: aws.String(volumeID)},
+			},
+			[]*ec2.TagDescription{
+				{Key: aws.String("tag2"), Value: aws.String("value2"), ResourceId: aws.String(volumeID)},
+				{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2"),
+					ResourceId: aws.String(volumeID)},
+			},
+		)).
+		Times(Once)
+
+	err := subject.EnsureResourceHasTags(volumeID, inputTags, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestEnsureResourceHasTags_ReturnsNoError_If_ResourceSucceedsTagging(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewEC2ResourceTagger(mockEc2Client, constants.ManagedKeysTag)
+
+	expectedCreateTagsInput := ec2.CreateTagsInput{
+		Resources: []*string{aws.String(volumeID)},
+		Tags: []*ec2.Tag{
+			{
+				Key:   aws.String("tag2"),
+				Value: aws.String("value2"),
+			},
+			{
+				Key:   aws.String(constants.ManagedKeysTag),
+				Value: aws.String("tag2"),
+			},
+		},
+	}
+
+	mockEc2Client.
+		EXPECT().
+		DescribeTagsPages(&expectedDescribeTagsInput, gomock.Any()).
+		DoAndReturn(describeTagsPages(
+			[]*ec2.TagDescription{
+				{Key: aws.String("tag1"), Value: aws.String("value1"), ResourceId: aws.String(volumeID)},
+			},
+		)).
+		Times(Once)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&expectedCreateTagsInput)).
+		Return(nil, nil).
+		Times(Once)
+
+	err := subject.EnsureResourceHasTags(volumeID, inputTags, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestEnsureResourceHasTags_DeletesUnneededManagedKeysTags_After_TaggingResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewEC2ResourceTagger(mockEc2Client, constants.ManagedKeysTag)
+
+	longKey1 := strings.Repeat("a", 128)
+	longKey2 := strings.Repeat("b", 128)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeTagsPages(&expectedDescribeTagsInput, gomock.Any()).
+		DoAndReturn(describeTagsPages(
+			[]*ec2.TagDescription{
+				{Key: aws.String(longKey1), Value: aws.String("value"), ResourceId: aws.String(volumeID)},
+				{Key: aws.String(longKey2), Value: aws.String("value"), ResourceId: aws.String(volumeID)},
+				{Key: aws.String(constants.ManagedKeysTag), Value: aws.String(longKey1),
+					ResourceId: aws.String(volumeID)},
+				{Key: aws.String(constants.ManagedKeysTag + "-1"), Value: aws.String(longKey2),
+					ResourceId: aws.String(volumeID)},
+			},
+		)).
+		Times(Once)
+
+	// The keys are recorded in the managed keys tag before the tag they were recorded in is deleted
+	gomock.InOrder(
+		mockEc2Client.
+			EXPECT().
+			DeleteTags(&ec2.DeleteTagsInput{
+				Resources: []*string{aws.String(volumeID)},
+				Tags:      []*ec2.Tag{{Key: aws.String(longKey1)}},
+			}).
+			Return(nil, nil).
+			Times(Once),
+		mockEc2Client.
+			EXPECT().
+			CreateTags(&ec2.CreateTagsInput{
+				Resources: []*string{aws.String(volumeID)},
+				Tags:      []*ec2.Tag{{Key: aws.String(constants.ManagedKeysTag), Value: aws.String(longKey2)}},
+			}).
+			Return(nil, nil).
+			Times(Once),
+		mockEc2Client.
+			EXPECT().
+			DeleteTags(&ec2.DeleteTagsInput{
+				Resources: []*string{aws.String(volumeID)},
+				Tags:      []*ec2.Tag{{Key: aws.String(constants.ManagedKeysTag + "-1")}},
+			}).
+			Return(nil, nil).
+			Times(Once),
+	)
+
+	err := subject.EnsureResourceHasTags(volumeID, map[string]string{longKey2: "value"}, nil)
+
+	assert.NoError(t, err)
+}
@@ -1,16 +1,66 @@
 package aws
 
-import "github.com/aws/aws-sdk-go/aws/session"
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
 
-// Gets the aws session
+// Gets the aws session. The calls of its clients are limited by the limits set with ConfigureAPILimits, and their
+// latency is observed
 func GetAwsSessionFromEnv() (*session.Session, error) {
-	sess, err := session.NewSessionWithOptions(session.Options{
+	options := session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	})
+	}
+
+	if sharedAPILimiter != nil {
+		options.Config = *request.WithRetryer(aws.NewConfig(), sharedAPILimiter.retryer())
+	}
+
+	sess, err := session.NewSessionWithOptions(options)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if sharedAPILimiter != nil {
+		sharedAPILimiter.apply(&sess.Handlers)
+	}
+
+	sharedAPILatency.apply(&sess.Handlers)
+
 	return sess, nil
 }
+
+// NewSessionEC2ClientFactory creates the EC2 clients of every region with the credentials of the session
+func NewSessionEC2ClientFactory(sess *session.Session) EC2ClientFactory {
+	return func(region string) (ec2iface.EC2API, error) {
+		return ec2.New(sess, aws.NewConfig().WithRegion(region)), nil
+	}
+}
+
+// NewAssumeRoleAccount tags the nodes matching the mapping through the EC2 API of their region, with the credentials of
+// the role of the mapping assumed through STS with the credentials of the session. The credentials are shared by every
+// region and refreshed before they expire.
+func NewAssumeRoleAccount(sess *session.Session, mapping AssumeRoleMapping, options NodeInstanceTaggerOptions) Account {
+	roleCredentials := stscreds.NewCredentials(sess, mapping.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = "node-tagger"
+
+		if mapping.ExternalID != "" {
+			p.ExternalID = aws.String(mapping.ExternalID)
+		}
+	})
+
+	newEC2Client := func(region string) (ec2iface.EC2API, error) {
+		return ec2.New(sess, aws.NewConfig().WithRegion(region).WithCredentials(roleCredentials)), nil
+	}
+
+	return Account{
+		Mapping:     mapping,
+		Credentials: roleCredentials,
+		NodeTagger:  NewRegionalNodeTagger(newEC2Client, options),
+	}
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// tagCache remembers the tags node-tagger last saw on every instance, so nodes whose instance already has the desired
+// tags are reconciled without calling the EC2 API. The tags are forgotten once their TTL expires, so tags changed by
+// anyone else are eventually seen again.
+type tagCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]tagCacheEntry
+}
+
+type tagCacheEntry struct {
+	tags []*ec2.Tag
+	// resyncToken is the value of the resync annotation of the node when the tags were read
+	resyncToken string
+	expiresAt   time.Time
+}
+
+// newTagCache creates a cache keeping the tags for the TTL. A zero TTL disables the cache.
+func newTagCache(ttl time.Duration) *tagCache {
+	return &tagCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]tagCacheEntry{},
+	}
+}
+
+func (c *tagCache) enabled() bool {
+	return c.ttl > 0
+}
+
+// get returns the known tags of the instance, unless they expired or the resync annotation of the node changed since
+// they were read
+func (c *tagCache) get(instanceID string, resyncToken string) ([]*ec2.Tag, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, found := c.entries[instanceID]
+	if !found {
+		return nil, false
+	}
+
+	if entry.resyncToken != resyncToken || !c.now().Before(entry.expiresAt) {
+		delete(c.entries, instanceID)
+		return nil, false
+	}
+
+	return entry.tags, true
+}
+
+func (c *tagCache) set(instanceID string, resyncToken string, tags []*ec2.Tag) {
+	if !c.enabled() {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[instanceID] = tagCacheEntry{
+		tags:        tags,
+		resyncToken: resyncToken,
+		expiresAt:   c.now().Add(c.ttl),
+	}
+}
+
+// clear forgets the tags of every instance
+func (c *tagCache) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries = map[string]tagCacheEntry{}
+}
@@ -0,0 +1,294 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTagCache_Get(t *testing.T) {
+	tags := []*ec2.Tag{{Key: aws.String("tag1"), Value: aws.String("value1")}}
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		ttl         time.Duration
+		age         time.Duration
+		resyncToken string
+		expectFound bool
+	}{
+		{
+			name:        "fresh tags",
+			ttl:         time.Minute,
+			age:         59 * time.Second,
+			expectFound: true,
+		},
+		{
+			name: "expired tags",
+			ttl:  time.Minute,
+			age:  time.Minute,
+		},
+		{
+			name:        "resync annotation changed",
+			ttl:         time.Minute,
+			resyncToken: "2020-06-01T10:00:00Z",
+		},
+		{
+			name: "disabled cache",
+			ttl:  0,
+		},
+	}
+
+	for _, testData := range testCases {
+		testData := testData // pin testData var in this scope
+		t.Run(testData.name, func(t *testing.T) {
+			subject := newTagCache(testData.ttl)
+			subject.now = func() time.Time { return now }
+
+			subject.set(instanceID, "", tags)
+			subject.now = func() time.Time { return now.Add(testData.age) }
+
+			cachedTags, found := subject.get(instanceID, testData.resyncToken)
+
+			assert.Equal(t, testData.expectFound, found)
+
+			if testData.expectFound {
+				assert.Equal(t, tags, cachedTags)
+			}
+		})
+	}
+}
+
+func TestEnsureInstanceNodeHasTags_SkipsEC2_If_InstanceKnownToBeTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{TagCacheTTL: time.Hour})
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///eu-west-1a/" + instanceID,
+		},
+	}
+
+	describeInstancesOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId: aws.String(instanceID),
+						Tags: []*ec2.Tag{
+							{Key: aws.String("tag1"), Value: aws.String("value1")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The first reconcile describes and tags the instance, the second one trusts the cache, the third one is forced
+	// to describe it again by the resync annotation and the last one by a resync of the tagger
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(instanceID)}}).
+		Return(describeInstancesOutput, nil).
+		Times(3)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(instanceID)},
+			Tags: []*ec2.Tag{
+				{Key: aws.String("tag2"), Value: aws.String("value2")},
+				{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag2")},
+			},
+		})).
+		Return(&ec2.CreateTagsOutput{}, nil).
+		Times(3)
+
+	hitsBefore := testutil.ToFloat64(metrics.TagCacheRequests.WithLabelValues("hit"))
+	missesBefore := testutil.ToFloat64(metrics.TagCacheRequests.WithLabelValues("miss"))
+
+	taggedResult := provider.NewTagResult([]string{"tag2"}, nil)
+
+	result, err := subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, taggedResult, result)
+
+	result, err = subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+
+	node.Annotations = map[string]string{constants.ResyncAnnotation: "1"}
+	result, err = subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, taggedResult, result)
+
+	subject.(provider.Resyncer).Resync()
+	result, err = subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, taggedResult, result)
+
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(metrics.TagCacheRequests.WithLabelValues("hit")))
+	assert.Equal(t, missesBefore+3, testutil.ToFloat64(metrics.TagCacheRequests.WithLabelValues("miss")))
+}
+
+func TestEnsureInstanceNodeHasTags_TagsVolumesAttachedSince_If_VolumesAreTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{
+		TagCacheTTL:    time.Hour,
+		TagDataVolumes: true,
+	})
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///eu-west-1a/" + instanceID,
+		},
+	}
+
+	instance := &ec2.Instance{
+		InstanceId: aws.String(instanceID),
+		Tags: []*ec2.Tag{
+			{Key: aws.String("tag1"), Value: aws.String("value1")},
+			{Key: aws.String("tag2"), Value: aws.String("value2")},
+			{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+		},
+	}
+
+	// The first reconcile finds the instance already tagged and no data volume, the second one is not skipped by the
+	// cache and finds the volume attached since
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(instanceID)}}).
+		DoAndReturn(func(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+			output := &ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{instance}}},
+			}
+
+			instance = &ec2.Instance{
+				InstanceId: instance.InstanceId,
+				Tags:       instance.Tags,
+				BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{
+					{
+						DeviceName: aws.String("/dev/xvdb"),
+						Ebs:        &ec2.EbsInstanceBlockDevice{VolumeId: aws.String("vol-data")},
+					},
+				},
+			}
+
+			return output, nil
+		}).
+		Times(2)
+
+	mockEc2Client.
+		EXPECT().
+		DescribeVolumes(&ec2.DescribeVolumesInput{VolumeIds: aws.StringSlice([]string{"vol-data"})}).
+		Return(&ec2.DescribeVolumesOutput{
+			Volumes: []*ec2.Volume{{VolumeId: aws.String("vol-data"), Tags: []*ec2.Tag{}}},
+		}, nil).
+		Times(Once)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String("vol-data")},
+			Tags: []*ec2.Tag{
+				{Key: aws.String("tag1"), Value: aws.String("value1")},
+				{Key: aws.String("tag2"), Value: aws.String("value2")},
+				{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag1,tag2")},
+			},
+		})).
+		Return(&ec2.CreateTagsOutput{}, nil).
+		Times(Once)
+
+	_, err := subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+
+	_, err = subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+}
+
+func TestEnsureInstanceNodeHasTags_DescribesInstance_If_DesiredTagsChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+
+	subject := NewNodeInstanceTagger(mockEc2Client, NodeInstanceTaggerOptions{TagCacheTTL: time.Hour})
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "aws:///eu-west-1a/" + instanceID,
+		},
+	}
+
+	describeInstancesOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{
+			{
+				Instances: []*ec2.Instance{
+					{
+						InstanceId: aws.String(instanceID),
+						Tags: []*ec2.Tag{
+							{Key: aws.String("tag1"), Value: aws.String("value1")},
+							{Key: aws.String("tag2"), Value: aws.String("value2")},
+							{Key: aws.String(constants.ManagedKeysTag), Value: aws.String("tag2")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mockEc2Client.
+		EXPECT().
+		DescribeInstances(gomock.Any()).
+		Return(describeInstancesOutput, nil).
+		Times(2)
+
+	mockEc2Client.
+		EXPECT().
+		CreateTags(CreateTagsInputMatcher(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(instanceID)},
+			Tags: []*ec2.Tag{
+				{Key: aws.String("tag1"), Value: aws.String("changed")},
+			},
+		})).
+		Return(&ec2.CreateTagsOutput{}, nil).
+		Times(Once)
+
+	_, err := subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+	assert.NoError(t, err)
+
+	result, err := subject.EnsureInstanceNodeHasTags(node, map[string]string{
+		"tag1": "changed",
+		"tag2": "value2",
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"tag1"}, nil), result)
+}
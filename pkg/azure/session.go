@@ -0,0 +1,78 @@
+package azure
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+const (
+	// ManagementEndpoint is the base URL of the Azure Resource Manager API
+	ManagementEndpoint = "https://management.azure.com/"
+
+	activeDirectoryEndpoint = "https://login.microsoftonline.com/"
+
+	TenantIDEnvVar     = "AZURE_TENANT_ID"
+	ClientIDEnvVar     = "AZURE_CLIENT_ID"
+	ClientSecretEnvVar = "AZURE_CLIENT_SECRET"
+)
+
+// GetAzureHTTPClientFromEnv returns an http client authenticating its requests to the Resource Manager API. It uses
+// the service principal from AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET when a secret is set, and the
+// managed identity of the instance otherwise, with AZURE_CLIENT_ID selecting a user assigned identity.
+func GetAzureHTTPClientFromEnv() (*http.Client, error) {
+	var token *adal.ServicePrincipalToken
+
+	clientID := os.Getenv(ClientIDEnvVar)
+
+	if secret := os.Getenv(ClientSecretEnvVar); secret != "" {
+		oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, os.Getenv(TenantIDEnvVar))
+		if err != nil {
+			return nil, err
+		}
+
+		token, err = adal.NewServicePrincipalToken(*oauthConfig, clientID, secret, ManagementEndpoint)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		msiEndpoint, err := adal.GetMSIVMEndpoint()
+		if err != nil {
+			return nil, err
+		}
+
+		if clientID != "" {
+			token, err = adal.NewServicePrincipalTokenFromMSIWithUserAssignedID(msiEndpoint, ManagementEndpoint, clientID)
+		} else {
+			token, err = adal.NewServicePrincipalTokenFromMSI(msiEndpoint, ManagementEndpoint)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &http.Client{
+		Transport: &tokenTransport{token: token, base: http.DefaultTransport},
+	}, nil
+}
+
+// tokenTransport adds the bearer token of a service principal to every request, refreshing it when it expires
+type tokenTransport struct {
+	token *adal.ServicePrincipalToken
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	err := t.token.EnsureFreshWithContext(request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests must not be modified by round trippers
+	authorized := request.Clone(request.Context())
+	authorized.Header.Set("Authorization", "Bearer "+t.token.OAuthToken())
+
+	return t.base.RoundTrip(authorized)
+}
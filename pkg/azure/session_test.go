@@ -0,0 +1,39 @@
+package azure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenTransport_AddsBearerToken(t *testing.T) {
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, "tenant")
+	assert.NoError(t, err)
+
+	token, err := adal.NewServicePrincipalTokenFromManualToken(*oauthConfig, "client", ManagementEndpoint, adal.Token{
+		AccessToken: "access-token",
+		ExpiresOn:   json.Number(strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)),
+	})
+	assert.NoError(t, err)
+
+	client := &http.Client{Transport: &tokenTransport{token: token, base: http.DefaultTransport}}
+
+	response, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	response.Body.Close()
+
+	assert.Equal(t, "Bearer access-token", authorization)
+}
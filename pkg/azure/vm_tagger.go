@@ -0,0 +1,294 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// computeAPIVersion is the first version of the Compute API returning the etags of the virtual machines
+	computeAPIVersion = "2022-11-01"
+
+	// maxUpdateAttempts bounds the retries when a virtual machine changes between reading and writing its tags
+	maxUpdateAttempts = 5
+
+	// MaxTagsPerResource is the maximum number of tags of an Azure resource
+	MaxTagsPerResource = 50
+
+	// invalidKeyCharacters can not be used in the keys of the tags of Azure resources
+	invalidKeyCharacters = `<>%&\?/`
+
+	// managedKeysTag records the keys of the tags node-tagger applied to a virtual machine, as the managed keys tag
+	// of the other providers, whose key contains a /, is not allowed by Azure. The keys that do not fit in its value
+	// overflow into numbered tags the same way
+	managedKeysTag = "node-tagger.ouzi.dev.managed-keys"
+)
+
+var log = logf.Log.WithName("azure_vm_tagger")
+
+// errVMChanged is returned when a virtual machine changed since its etag was read
+var errVMChanged = errors.New("virtual machine changed")
+
+// vmRef identifies a virtual machine or a virtual machine of a scale set by its resource ID
+type vmRef struct {
+	resourceID string
+	scaleSetVM bool
+}
+
+type vmTagger struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewVMTagger returns a NodeTagger applying the tags to the virtual machines and scale set virtual machines of the
+// nodes. The http client must add the credentials to the requests, e.g. one from GetAzureHTTPClientFromEnv.
+func NewVMTagger(httpClient *http.Client, endpoint string) provider.NodeTagger {
+	return &vmTagger{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/") + "/",
+	}
+}
+
+func (a *vmTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	ref, err := parseProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	nodeLogger := log.WithValues("Node.Name", node.Name, "VM.ID", ref.resourceID)
+
+	err = validateTags(tags)
+	if err != nil {
+		return provider.TagResult{}, errors.Wrapf(err, "node %s", node.Name)
+	}
+
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		result, err := a.tagVM(nodeLogger, ref, tags, conflictPolicies)
+		if err == errVMChanged {
+			nodeLogger.V(constants.DebugLogVerbosity).Info("VM changed concurrently. Retrying", "Attempt", attempt)
+			continue
+		}
+
+		return result, err
+	}
+
+	return provider.TagResult{}, errors.Errorf("VM %s kept changing concurrently, gave up after %d attempts",
+		ref.resourceID, maxUpdateAttempts)
+}
+
+// tagVM reads the tags of the virtual machine and writes the merged ones back, only if the virtual machine did not
+// change in between according to its etag, so tags written concurrently by anyone else are never lost
+func (a *vmTagger) tagVM(nodeLogger logr.Logger, ref vmRef, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	vm := map[string]interface{}{}
+
+	err := a.do(http.MethodGet, ref.resourceID, "", nil, &vm)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	existingTags := map[string]string{}
+	if vmTags, ok := vm["tags"].(map[string]interface{}); ok {
+		for key, value := range vmTags {
+			existingTags[key], _ = value.(string)
+		}
+	}
+
+	managedKeysTags := map[string]string{}
+
+	for key, value := range existingTags {
+		if tagging.IsManagedKeysTag(managedKeysTag, key) {
+			managedKeysTags[key] = value
+			delete(existingTags, key)
+		}
+	}
+
+	merged, err := tagging.Merge(existingTags, tagging.ParseManagedKeys(managedKeysTag, managedKeysTags), tags,
+		conflictPolicies)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	desiredManagedKeysTags, err := tagging.FormatManagedKeys(managedKeysTag, merged.ManagedKeys)
+	if err != nil {
+		return provider.TagResult{}, errors.Wrapf(err, "failed to tag VM %s", ref.resourceID)
+	}
+
+	if !merged.Changed() && reflect.DeepEqual(managedKeysTags, desiredManagedKeysTags) {
+		nodeLogger.V(constants.DebugLogVerbosity).Info("VM already tagged.")
+		return provider.TagResult{}, nil
+	}
+
+	// The managed keys tags no longer needed are left out, as the tags are written as a whole
+	mergedTags := merged.Tags
+	for key, value := range desiredManagedKeysTags {
+		mergedTags[key] = value
+	}
+
+	if len(mergedTags) > MaxTagsPerResource {
+		return provider.TagResult{}, errors.Errorf("VM %s would have %d tags, the maximum is %d", ref.resourceID,
+			len(mergedTags), MaxTagsPerResource)
+	}
+
+	nodeLogger.Info("Tagging VM.")
+
+	etag, _ := vm["etag"].(string)
+
+	// The tags of a virtual machine can be patched on their own, while a scale set virtual machine is only updated
+	// as a whole
+	if ref.scaleSetVM {
+		vm["tags"] = mergedTags
+		err = a.do(http.MethodPut, ref.resourceID, etag, vm, nil)
+	} else {
+		err = a.do(http.MethodPatch, ref.resourceID, etag, map[string]interface{}{"tags": mergedTags}, nil)
+	}
+
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	return provider.NewTagResult(merged.SetKeys, merged.RemovedKeys), nil
+}
+
+// do calls the Resource Manager API, only writing when the etag of the resource still matches ifMatch if set
+func (a *vmTagger) do(method string, resourceID string, ifMatch string, body interface{}, result interface{}) error {
+	requestURL := a.endpoint + strings.TrimPrefix(resourceID, "/") + "?api-version=" + computeAPIVersion
+
+	var requestBody []byte
+
+	if body != nil {
+		var err error
+
+		requestBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest(method, requestURL, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	if ifMatch != "" {
+		request.Header.Set("If-Match", ifMatch)
+	}
+
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusPreconditionFailed {
+		return errVMChanged
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = errors.Errorf("%s %s failed with status %s: %s", method, resourceID, response.Status,
+			strings.TrimSpace(string(responseBody)))
+
+		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+			return &provider.PermissionDeniedError{Err: err}
+		}
+
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(responseBody, result)
+}
+
+// parseProviderID parses the ProviderIDs of virtual machines,
+// azure:///subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.Compute/virtualMachines/<name>, and of scale
+// set virtual machines, .../virtualMachineScaleSets/<name>/virtualMachines/<instance id>
+func parseProviderID(providerID string) (vmRef, error) {
+	invalid := errors.Errorf("invalid Azure ProviderID %q, must be azure:///subscriptions/<id>/resourceGroups/<group>"+
+		"/providers/Microsoft.Compute/virtualMachines/<name> or a virtual machine of a scale set", providerID)
+
+	if !strings.HasPrefix(providerID, "azure://") {
+		return vmRef{}, invalid
+	}
+
+	resourceID := strings.TrimPrefix(providerID, "azure://")
+	segments := strings.Split(strings.TrimPrefix(resourceID, "/"), "/")
+
+	for _, segment := range segments {
+		if segment == "" {
+			return vmRef{}, invalid
+		}
+	}
+
+	isPrefix := len(segments) >= 8 &&
+		strings.EqualFold(segments[0], "subscriptions") &&
+		strings.EqualFold(segments[2], "resourceGroups") &&
+		strings.EqualFold(segments[4], "providers") &&
+		strings.EqualFold(segments[5], "Microsoft.Compute")
+
+	switch {
+	case isPrefix && len(segments) == 8 && strings.EqualFold(segments[6], "virtualMachines"):
+		return vmRef{resourceID: resourceID}, nil
+	case isPrefix && len(segments) == 10 && strings.EqualFold(segments[6], "virtualMachineScaleSets") &&
+		strings.EqualFold(segments[8], "virtualMachines"):
+		return vmRef{resourceID: resourceID, scaleSetVM: true}, nil
+	default:
+		return vmRef{}, invalid
+	}
+}
+
+// validateTags checks the tags against the rules of Azure on top of the ones checked for every provider, as Azure
+// tag keys do not allow some characters common in AWS ones, such as the / of kubernetes.io/cluster
+func validateTags(tags map[string]string) error {
+	problems := []string{}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if tagging.IsManagedKeysTag(managedKeysTag, key) {
+			problems = append(problems, fmt.Sprintf("key %q is reserved for node-tagger", key))
+		}
+
+		if strings.ContainsAny(key, invalidKeyCharacters) {
+			problems = append(problems, fmt.Sprintf("key %q contains one of the characters %s not allowed by Azure",
+				key, invalidKeyCharacters))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &tagging.ValidationError{Problems: problems}
+	}
+
+	return nil
+}
@@ -0,0 +1,393 @@
+package azure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	vmID         = "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Compute/virtualMachines/vm"
+	scaleSetVMID = "/subscriptions/sub/resourceGroups/mc_group/providers/Microsoft.Compute" +
+		"/virtualMachineScaleSets/aks-nodepool1-vmss/virtualMachines/3"
+)
+
+var inputTags = map[string]string{
+	"team":        "platform",
+	"cost-center": "1234",
+}
+
+func newNode(resourceID string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "aks-node",
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: "azure://" + resourceID,
+		},
+	}
+}
+
+// fakeResourceManager is an in-process stand-in of the virtual machine endpoints of the Resource Manager API
+type fakeResourceManager struct {
+	mu        sync.Mutex
+	resources map[string]map[string]interface{}
+	requests  []string
+	// forbiddenMethod is denied to the identity of node-tagger
+	forbiddenMethod string
+	// version is bumped on every change to a resource, and returned as its etag
+	version int
+	// concurrentChanges is the number of writes that find the resource changed by someone else
+	concurrentChanges int
+}
+
+func newFakeResourceManager(t *testing.T,
+	resources map[string]map[string]interface{}) (*fakeResourceManager, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeResourceManager{resources: resources}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeResourceManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method)
+
+	if r.URL.Query().Get("api-version") != computeAPIVersion {
+		http.Error(w, `{"error": {"code": "MissingApiVersionParameter"}}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == f.forbiddenMethod {
+		http.Error(w, `{"error": {"code": "AuthorizationFailed"}}`, http.StatusForbidden)
+		return
+	}
+
+	resource, found := f.resources[r.URL.Path]
+	if !found {
+		http.Error(w, `{"error": {"code": "ResourceNotFound"}}`, http.StatusNotFound)
+		return
+	}
+
+	body := map[string]interface{}{}
+	if r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	etag := `"` + strconv.Itoa(f.version) + `"`
+
+	if r.Method != http.MethodGet {
+		if f.concurrentChanges > 0 {
+			f.concurrentChanges--
+			f.version++
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" || ifMatch != `"`+strconv.Itoa(f.version)+`"` {
+			http.Error(w, `{"error": {"code": "PreconditionFailed"}}`, http.StatusPreconditionFailed)
+			return
+		}
+
+		f.version++
+		delete(body, "etag")
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		// Patching replaces the tags of the resource as a whole
+		resource["tags"] = body["tags"]
+	case http.MethodPut:
+		f.resources[r.URL.Path] = body
+		resource = body
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := map[string]interface{}{"etag": etag}
+	for key, value := range resource {
+		response[key] = value
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestParseProviderID(t *testing.T) {
+	tests := []struct {
+		providerID    string
+		expected      vmRef
+		expectedError bool
+	}{
+		{providerID: "azure://" + vmID, expected: vmRef{resourceID: vmID}},
+		{providerID: "azure://" + scaleSetVMID, expected: vmRef{resourceID: scaleSetVMID, scaleSetVM: true}},
+		{
+			providerID: "azure:///subscriptions/sub/resourcegroups/group/providers/microsoft.compute/virtualmachines/vm",
+			expected: vmRef{
+				resourceID: "/subscriptions/sub/resourcegroups/group/providers/microsoft.compute/virtualmachines/vm",
+			},
+		},
+		{providerID: "azure:///subscriptions/sub/resourceGroups/group", expectedError: true},
+		{providerID: "azure:///subscriptions/sub/resourceGroups//providers/Microsoft.Compute/virtualMachines/vm",
+			expectedError: true},
+		{providerID: "aws://" + vmID, expectedError: true},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.providerID, func(t *testing.T) {
+			ref, err := parseProviderID(testData.providerID)
+
+			if testData.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, ref)
+		})
+	}
+}
+
+func TestEnsureInstanceNodeHasTags_PatchesVMTags(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		vmID: {
+			"name": "vm",
+			"tags": map[string]interface{}{"environment": "production", "team": "platform"},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	result, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"cost-center"}, nil), result)
+	assert.Equal(t, []string{http.MethodGet, http.MethodPatch}, fake.requests)
+	assert.Equal(t, map[string]interface{}{
+		"environment":  "production",
+		"team":         "platform",
+		"cost-center":  "1234",
+		managedKeysTag: "cost-center",
+	}, fake.resources[vmID]["tags"])
+}
+
+func TestEnsureInstanceNodeHasTags_PutsScaleSetVMWithTags(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		scaleSetVMID: {
+			"name":       "aks-nodepool1-vmss_3",
+			"properties": map[string]interface{}{"latestModelApplied": true},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(scaleSetVMID), inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{http.MethodGet, http.MethodPut}, fake.requests)
+	assert.Equal(t, map[string]interface{}{
+		"name":       "aks-nodepool1-vmss_3",
+		"properties": map[string]interface{}{"latestModelApplied": true},
+		"tags": map[string]interface{}{
+			"team":         "platform",
+			"cost-center":  "1234",
+			managedKeysTag: "cost-center,team",
+		},
+	}, fake.resources[scaleSetVMID])
+}
+
+func TestEnsureInstanceNodeHasTags_RecordsManagedKeysInNumberedTags_If_TheyOverflow(t *testing.T) {
+	longKey1 := strings.Repeat("a", 128)
+	longKey2 := strings.Repeat("b", 128)
+
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		vmID: {
+			"tags": map[string]interface{}{"team": "platform"},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{longKey1: "1", longKey2: "2"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"team":                "platform",
+		longKey1:              "1",
+		longKey2:              "2",
+		managedKeysTag:        longKey1,
+		managedKeysTag + "-1": longKey2,
+	}, fake.resources[vmID]["tags"])
+
+	_, err = subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{longKey2: "2"}, nil)
+
+	// The numbered tag is dropped once the keys fit in the managed keys tag again
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"team":         "platform",
+		longKey2:       "2",
+		managedKeysTag: longKey2,
+	}, fake.resources[vmID]["tags"])
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_VMAlreadyTagged(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		vmID: {
+			"tags": map[string]interface{}{"team": "platform", "cost-center": "1234"},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	result, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+	assert.Equal(t, []string{http.MethodGet}, fake.requests)
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_VMNotFound(t *testing.T) {
+	_, server := newFakeResourceManager(t, map[string]map[string]interface{}{})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+
+	assert.EqualError(t, err, "GET "+vmID+` failed with status 404 Not Found: {"error": {"code": "ResourceNotFound"}}`)
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsPermissionDeniedError_If_TaggingIsForbidden(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{vmID: {}})
+	fake.forbiddenMethod = http.MethodPatch
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+
+	assert.EqualError(t, err, "PATCH "+vmID+` failed with status 403 Forbidden: `+
+		`{"error": {"code": "AuthorizationFailed"}}`)
+	assert.IsType(t, &provider.PermissionDeniedError{}, err)
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_KeysAreInvalidForAzure(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{vmID: {}})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{"kubernetes.io/cluster": "owned"}, nil)
+
+	assert.EqualError(t, err, `node aks-node: invalid tags: key "kubernetes.io/cluster" contains one of the `+
+		`characters <>%&\?/ not allowed by Azure`)
+	assert.Empty(t, fake.requests)
+}
+
+func TestEnsureInstanceNodeHasTags_ResolvesConflicts(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		vmID: {
+			"tags": map[string]interface{}{"team": "terraform"},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, tagging.ConflictPolicies{
+		"team": tagging.ConflictPolicyFail,
+	})
+
+	assert.Equal(t, &tagging.ConflictError{
+		Conflicts: []tagging.Conflict{
+			{Key: "team", ExistingValue: "terraform", DesiredValue: "platform"},
+		},
+	}, err)
+	assert.Equal(t, []string{http.MethodGet}, fake.requests)
+}
+
+func TestEnsureInstanceNodeHasTags_RemovesManagedTagsNoLongerDesired(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		vmID: {
+			"tags": map[string]interface{}{"environment": "production"},
+		},
+	})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+	assert.NoError(t, err)
+
+	// A changed value of a managed tag is not a conflict
+	result, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{"team": "data"},
+		tagging.ConflictPolicies{"team": tagging.ConflictPolicyFail})
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"team"}, []string{"cost-center"}), result)
+	assert.Equal(t, map[string]interface{}{
+		"environment":  "production",
+		"team":         "data",
+		managedKeysTag: "team",
+	}, fake.resources[vmID]["tags"])
+
+	result, err = subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult(nil, []string{"team"}), result)
+	assert.Equal(t, map[string]interface{}{"environment": "production"}, fake.resources[vmID]["tags"])
+}
+
+func TestEnsureInstanceNodeHasTags_RetriesOnConcurrentChanges(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{
+		scaleSetVMID: {
+			"name": "aks-nodepool1-vmss_3",
+		},
+	})
+	fake.concurrentChanges = 2
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(scaleSetVMID), inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		http.MethodGet, http.MethodPut,
+		http.MethodGet, http.MethodPut,
+		http.MethodGet, http.MethodPut,
+	}, fake.requests)
+	assert.Equal(t, map[string]interface{}{
+		"name": "aks-nodepool1-vmss_3",
+		"tags": map[string]interface{}{
+			"team":         "platform",
+			"cost-center":  "1234",
+			managedKeysTag: "cost-center,team",
+		},
+	}, fake.resources[scaleSetVMID])
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_VMKeepsChanging(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{vmID: {}})
+	fake.concurrentChanges = maxUpdateAttempts
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), inputTags, nil)
+
+	assert.EqualError(t, err, "VM "+vmID+" kept changing concurrently, gave up after 5 attempts")
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_KeysAreReservedForNodeTagger(t *testing.T) {
+	fake, server := newFakeResourceManager(t, map[string]map[string]interface{}{vmID: {}})
+	subject := NewVMTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(newNode(vmID), map[string]string{managedKeysTag + "-1": "team"}, nil)
+
+	assert.EqualError(t, err, `node aks-node: invalid tags: key "node-tagger.ouzi.dev.managed-keys-1" is reserved `+
+		`for node-tagger`)
+	assert.Empty(t, fake.requests)
+}
@@ -2,4 +2,29 @@ package constants
 
 const (
 	DebugLogVerbosity = 1
+
+	// TagsAnnotation holds a JSON object of extra tags for a single resource, overriding any other source of tags
+	TagsAnnotation = "node-tagger.ouzi.dev/tags"
+	// SkipAnnotation excludes a node from tagging when set to "true"
+	SkipAnnotation = "node-tagger.ouzi.dev/skip"
+	// ResyncAnnotation forces node-tagger to read the tags of the instance of a node from the cloud provider again
+	// instead of trusting the ones it last saw, whenever its value changes
+	ResyncAnnotation = "node-tagger.ouzi.dev/resync"
+
+	// AccountIDLabel holds the ID of the AWS account of the instance of a node, selecting the role node-tagger assumes
+	// to tag it
+	AccountIDLabel = "node-tagger.ouzi.dev/account-id"
+
+	// ManagedKeysTag is the tag recording the comma separated keys of the tags node-tagger applied to a resource, so
+	// they can be removed once they are no longer desired without touching the tags applied by anyone else. The keys
+	// that do not fit in its value overflow into ManagedKeysTag-1, ManagedKeysTag-2 and so on
+	ManagedKeysTag = "node-tagger.ouzi.dev/managed-keys"
+	// VolumeManagedKeysTag records the keys of the tags node-tagger applied to an EBS volume from its
+	// PersistentVolumeClaim, apart from the ones applied from the node the volume is attached to
+	VolumeManagedKeysTag = "node-tagger.ouzi.dev/volume-managed-keys"
+	// ManagedKeysSeparator separates the keys in the ManagedKeysTag and the VolumeManagedKeysTag
+	ManagedKeysSeparator = ","
+
+	// EBSCSIDriver is the name of the CSI driver provisioning EBS volumes and snapshots
+	EBSCSIDriver = "ebs.csi.aws.com"
 )
@@ -0,0 +1,88 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// eventDeduplicationInterval is how long an event is not recorded again on the same object. It matches the default
+// time to live of the events in the API server, so a node failing for longer still shows the event.
+const eventDeduplicationInterval = time.Hour
+
+// dedupingRecorder records an event on an object only when it differs from the last one recorded on the object, or
+// when the last one is older than the interval, so nodes failing on every retry do not flood their events. Past and
+// annotated events are recorded as they are.
+type dedupingRecorder struct {
+	record.EventRecorder
+	interval time.Duration
+	now      func() time.Time
+
+	lock sync.Mutex
+	last map[string]recordedEvent
+}
+
+// recordedEvent is the last event recorded on an object
+type recordedEvent struct {
+	eventType  string
+	reason     string
+	message    string
+	recordedAt time.Time
+}
+
+func newDedupingRecorder(recorder record.EventRecorder, interval time.Duration) *dedupingRecorder {
+	return &dedupingRecorder{
+		EventRecorder: recorder,
+		interval:      interval,
+		now:           time.Now,
+		last:          map[string]recordedEvent{},
+	}
+}
+
+func (d *dedupingRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if d.isDuplicate(object, eventType, reason, message) {
+		return
+	}
+
+	d.EventRecorder.Event(object, eventType, reason, message)
+}
+
+func (d *dedupingRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	d.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// isDuplicate tells whether the same event was recorded on the object within the interval, remembering the event
+// otherwise
+func (d *dedupingRecorder) isDuplicate(object runtime.Object, eventType, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return false
+	}
+
+	key := accessor.GetNamespace() + "/" + accessor.GetName()
+	event := recordedEvent{eventType: eventType, reason: reason, message: message, recordedAt: d.now()}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	last, found := d.last[key]
+	if found && event.recordedAt.Sub(last.recordedAt) < d.interval &&
+		last.eventType == eventType && last.reason == reason && last.message == message {
+		return true
+	}
+
+	// The events of deleted objects are forgotten once they expire
+	for otherKey, other := range d.last {
+		if event.recordedAt.Sub(other.recordedAt) >= d.interval {
+			delete(d.last, otherKey)
+		}
+	}
+
+	d.last[key] = event
+
+	return false
+}
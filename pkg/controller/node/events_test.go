@@ -0,0 +1,48 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDedupingRecorder(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	now := time.Now()
+
+	subject := newDedupingRecorder(recorder, time.Hour)
+	subject.now = func() time.Time {
+		return now
+	}
+
+	otherNode := awsNode.DeepCopy()
+	otherNode.Name = "other"
+
+	subject.Event(awsNode, corev1.EventTypeWarning, "InstanceNotFound", "not found")
+	subject.Eventf(awsNode, corev1.EventTypeWarning, "InstanceNotFound", "not %s", "found")
+	subject.Event(otherNode, corev1.EventTypeWarning, "InstanceNotFound", "not found")
+
+	assert.Equal(t, "Warning InstanceNotFound not found", <-recorder.Events)
+	assert.Equal(t, "Warning InstanceNotFound not found", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+
+	// A different event is recorded, and so is the previous one again afterwards
+	subject.Event(awsNode, corev1.EventTypeNormal, "Tagged", "Applied tags: tag1")
+	subject.Event(awsNode, corev1.EventTypeWarning, "InstanceNotFound", "not found")
+
+	assert.Equal(t, "Normal Tagged Applied tags: tag1", <-recorder.Events)
+	assert.Equal(t, "Warning InstanceNotFound not found", <-recorder.Events)
+
+	// The same event is recorded again once the last one is older than the interval
+	now = now.Add(time.Hour)
+	subject.Event(awsNode, corev1.EventTypeWarning, "InstanceNotFound", "not found")
+
+	assert.Equal(t, "Warning InstanceNotFound not found", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+
+	// The events of the other node expired and were forgotten
+	assert.Len(t, subject.last, 1)
+}
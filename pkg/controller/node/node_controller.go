@@ -2,29 +2,50 @@ package node
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ouzi-dev/node-tagger/pkg/constants"
 
-	"github.com/aws/aws-sdk-go/service/ec2"
+	pkgerrors "github.com/pkg/errors"
 
+	"github.com/ouzi-dev/node-tagger/pkg/apis/nodetagger/v1alpha1"
 	"github.com/ouzi-dev/node-tagger/pkg/aws"
 	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/prometheus/client_golang/prometheus"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var log = logf.Log.WithName("controller_node")
 
+// Outcomes of the attempts to tag the instance of a node, counted by metrics.TaggingAttempts
+const (
+	outcomeAlreadyTagged = "already-tagged"
+	outcomeTagged        = "tagged"
+	outcomeNotFound      = "not-found"
+	outcomeAmbiguous     = "ambiguous"
+	outcomeError         = "error"
+)
+
 /**
 * USER ACTION REQUIRED: This is a scaffold file intended for the user to modify with their own Controller
 * business logic.  Delete these comments after modifying this file.*
@@ -42,34 +63,88 @@ func Add(mgr manager.Manager) error {
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
-	awsSession, err := aws.GetAwsSessionFromEnv()
+func newReconciler(mgr manager.Manager) (*ReconcileNode, error) {
+	providers, err := newProviderRegistry(flags.Providers)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReconcileNode{
-		client:     mgr.GetClient(),
-		scheme:     mgr.GetScheme(),
-		nodeTagger: aws.NewNodeInstanceTagger(ec2.New(awsSession)),
+		client:    mgr.GetClient(),
+		scheme:    mgr.GetScheme(),
+		recorder:  newDedupingRecorder(mgr.GetEventRecorderFor("node-tagger"), eventDeduplicationInterval),
+		providers: providers,
 	}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r *ReconcileNode) error {
 	// Create a new controller
-	c, err := controller.New("node-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("node-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: flags.MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource Node, ignoring the status updates made by the kubelets
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{}, nodePredicates)
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to NodeTagPolicies and requeue the Nodes affected by them
+	err = c.Watch(&source.Kind{Type: &v1alpha1.NodeTagPolicy{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &policyMapper{client: mgr.GetClient()},
+	})
 	if err != nil {
 		return err
 	}
 
-	// Watch for changes to primary resource Node
-	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{})
+	if flags.ResyncPeriod == 0 {
+		return nil
+	}
+
+	// Requeue every Node periodically to restore the tags changed outside node-tagger
+	resyncEvents := make(chan event.GenericEvent)
+
+	err = c.Watch(&source.Channel{Source: resyncEvents}, &handler.EnqueueRequestForObject{})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return mgr.Add(&nodeResyncer{
+		client:    mgr.GetClient(),
+		providers: r.providers,
+		period:    flags.ResyncPeriod,
+		events:    resyncEvents,
+	})
+}
+
+// nodePredicates filter the events of the Nodes. Deleted Nodes are reconciled too, so what node-tagger remembers
+// about them is forgotten and they are no longer counted as non compliant.
+var nodePredicates = predicate.Funcs{
+	UpdateFunc: nodeChanged,
+}
+
+// nodeChanged tells whether an update of a node can change the tags of its instance: a new ProviderID, or new labels
+// or annotations, which select the NodeTagPolicies, set the tags annotation and the skip and resync annotations, and
+// can be used by templated tag values. The periodic resyncs of the cache, whose objects are unchanged, also pass so
+// tags changed outside node-tagger are eventually restored. The status updates the kubelets make every few seconds
+// are ignored.
+func nodeChanged(e event.UpdateEvent) bool {
+	oldNode, oldOk := e.ObjectOld.(*corev1.Node)
+	newNode, newOk := e.ObjectNew.(*corev1.Node)
+
+	if !oldOk || !newOk {
+		return false
+	}
+
+	return oldNode.ResourceVersion == newNode.ResourceVersion ||
+		oldNode.Spec.ProviderID != newNode.Spec.ProviderID ||
+		!labels.Equals(oldNode.Labels, newNode.Labels) ||
+		!labels.Equals(oldNode.Annotations, newNode.Annotations)
 }
 
 // blank assignment to verify that ReconcileNode implements reconcile.Reconciler
@@ -79,12 +154,21 @@ var _ reconcile.Reconciler = &ReconcileNode{}
 type ReconcileNode struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client     client.Client
-	scheme     *runtime.Scheme
-	nodeTagger aws.NodeTagger
+	client    client.Client
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	providers *provider.Registry
+	// appliedTags holds the ProviderID and tags last applied for each Node, so changes made to tags that were already
+	// applied are reported as drift
+	appliedTags sync.Map
+	// nonCompliantNodes holds the provider of each Node whose last attempt to tag its instance failed
+	nonCompliantNodes sync.Map
+	// unknownProviderNodes holds the scheme of each Node skipped because no provider is enabled for it
+	unknownProviderNodes sync.Map
 }
 
-// Reconcile reads that state of the cluster for a Node object and adds tags to the underlying instances if necessary
+// Reconcile reads that state of the cluster for a Node object and adds tags to the underlying instances if necessary,
+// through the provider matching the scheme of the ProviderID of the node
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileNode) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -99,26 +183,281 @@ func (r *ReconcileNode) Reconcile(request reconcile.Request) (reconcile.Result,
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Return and don't requeue
+			r.appliedTags.Delete(request.Name)
+			r.recordCompliance(request.Name, "", true)
+			r.recordUnknownProvider(request.Name, "")
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 
-	// Node is not an aws one so skip it. Return and don't requeue
-	if !isAwsNode(instance) {
-		reqLogger.V(constants.DebugLogVerbosity).Info("Node is not an AWS node. Skipping")
+	// Node is not initialised by its cloud provider yet. Return and don't requeue, setting the ProviderID will
+	// trigger a new reconcile
+	scheme := provider.Scheme(instance.Spec.ProviderID)
+	if scheme == "" {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Node has no ProviderID. Skipping")
+		r.recordCompliance(instance.Name, "", true)
+		r.recordUnknownProvider(instance.Name, "")
+		return reconcile.Result{}, nil
+	}
+
+	// Node runs on a provider that is not enabled so skip it. Return and don't requeue
+	nodeTagger, found := r.providers.Get(scheme)
+	if !found {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Node provider is not enabled. Skipping", "Provider", scheme)
+		r.recordCompliance(instance.Name, "", true)
+		r.recordUnknownProvider(instance.Name, scheme)
 		return reconcile.Result{}, nil
 	}
 
-	err = r.nodeTagger.EnsureInstanceNodeHasTags(instance, flags.InstanceTags)
+	r.recordUnknownProvider(instance.Name, "")
+
+	// Node has opted out of tagging so skip it. Return and don't requeue
+	if isSkippedNode(instance) {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Node has the skip annotation. Skipping")
+		r.recordCompliance(instance.Name, "", true)
+		return reconcile.Result{}, nil
+	}
+
+	tags, conflictPolicies, err := r.renderedTags(instance)
+
+	result := provider.TagResult{}
+	if err == nil {
+		result, err = nodeTagger.EnsureInstanceNodeHasTags(instance, tags, conflictPolicies)
+	}
+
+	metrics.TaggingAttempts.WithLabelValues(scheme, taggingOutcome(result, err)).Inc()
+	r.recordCompliance(instance.Name, scheme, err == nil)
+
 	if err != nil {
+		// Retrying cannot find the region of the node, so return and don't requeue. Setting the region label or the
+		// ProviderID will trigger a new reconcile
+		var regionErr *aws.RegionError
+		if pkgerrors.As(err, &regionErr) {
+			reqLogger.Info("Node region is unknown. Skipping", "Error", regionErr.Error())
+			r.recorder.Event(instance, corev1.EventTypeWarning, "UnknownRegion", regionErr.Error())
+			metrics.UnknownRegionNodes.Inc()
+
+			return reconcile.Result{}, nil
+		}
+
+		r.recordFailure(instance, err)
+
 		return reconcile.Result{}, err
 	}
 
+	// The same tags were applied before, so any change means the tags were edited or deleted outside node-tagger
+	fingerprint := appliedFingerprint(instance, tags, conflictPolicies)
+	applied, found := r.appliedTags.Load(instance.Name)
+
+	switch {
+	case !result.Changed():
+	case found && applied == fingerprint:
+		reqLogger.Info("Corrected tag drift.", "Tag.Keys", result.Keys())
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, "TagDrift",
+			"Restored tags changed outside node-tagger: %s", strings.Join(result.Keys(), ", "))
+	default:
+		r.recorder.Event(instance, corev1.EventTypeNormal, "Tagged", describeTagResult(result))
+	}
+
+	r.appliedTags.Store(instance.Name, fingerprint)
+
 	return reconcile.Result{}, nil
 }
 
-func isAwsNode(node *corev1.Node) bool {
-	return strings.HasPrefix(node.Spec.ProviderID, "aws")
+// recordFailure records a warning event on the node for the failures that need someone to act, as they are not fixed
+// by retrying on their own
+func (r *ReconcileNode) recordFailure(node *corev1.Node, err error) {
+	var conflictErr *tagging.ConflictError
+	var validationErr *tagging.ValidationError
+	var permissionErr *provider.PermissionDeniedError
+	var notFoundErr *aws.InstanceNotFoundError
+	var multipleInstancesErr *aws.MultipleInstancesError
+
+	switch {
+	case pkgerrors.As(err, &conflictErr):
+		r.recorder.Event(node, corev1.EventTypeWarning, "TagConflict", conflictErr.Error())
+	case pkgerrors.As(err, &validationErr):
+		r.recorder.Event(node, corev1.EventTypeWarning, "InvalidTags", err.Error())
+	case pkgerrors.As(err, &permissionErr):
+		r.recorder.Event(node, corev1.EventTypeWarning, "PermissionDenied", err.Error())
+	case pkgerrors.As(err, &notFoundErr):
+		r.recorder.Event(node, corev1.EventTypeWarning, "InstanceNotFound", err.Error())
+	case pkgerrors.As(err, &multipleInstancesErr):
+		r.recorder.Event(node, corev1.EventTypeWarning, "MultipleInstances", err.Error())
+	}
+}
+
+// describeTagResult lists the keys of the tags written to the instance of a node and its resources
+func describeTagResult(result provider.TagResult) string {
+	descriptions := []string{}
+
+	if len(result.SetKeys) > 0 {
+		descriptions = append(descriptions, "Applied tags: "+strings.Join(result.SetKeys, ", "))
+	}
+
+	if len(result.RemovedKeys) > 0 {
+		descriptions = append(descriptions, "Removed tags: "+strings.Join(result.RemovedKeys, ", "))
+	}
+
+	return strings.Join(descriptions, ". ")
+}
+
+// renderedTags computes the desired tags of the node, with their templated values rendered against it
+func (r *ReconcileNode) renderedTags(node *corev1.Node) (map[string]string, tagging.ConflictPolicies, error) {
+	tags, conflictPolicies, err := r.desiredTags(node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The instance is still checked so the tags node-tagger applied before are removed
+	if len(tags) == 0 {
+		log.V(constants.DebugLogVerbosity).Info("Node does not match any tags", "Node.Name", node.Name)
+	}
+
+	tags, err = tagging.Render(tags, tagging.NewNodeData(node))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tagging.Validate(tags)
+	if err != nil {
+		return nil, nil, pkgerrors.Wrapf(err, "node %s", node.Name)
+	}
+
+	return tags, conflictPolicies, nil
+}
+
+// taggingOutcome classifies an attempt to tag the instance of a node
+func taggingOutcome(result provider.TagResult, err error) string {
+	var notFoundErr *aws.InstanceNotFoundError
+	var multipleInstancesErr *aws.MultipleInstancesError
+
+	switch {
+	case err == nil && result.Changed():
+		return outcomeTagged
+	case err == nil:
+		return outcomeAlreadyTagged
+	case pkgerrors.As(err, &notFoundErr):
+		return outcomeNotFound
+	case pkgerrors.As(err, &multipleInstancesErr):
+		return outcomeAmbiguous
+	default:
+		return outcomeError
+	}
+}
+
+// recordCompliance keeps metrics.NonCompliantNodes up to date with the outcome of the last attempt to tag the instance
+// of a node. Nodes that are skipped or deleted are compliant, as node-tagger no longer has to tag them.
+func (r *ReconcileNode) recordCompliance(nodeName string, scheme string, compliant bool) {
+	if compliant {
+		scheme = ""
+	}
+
+	recordNode(&r.nonCompliantNodes, metrics.NonCompliantNodes, nodeName, scheme)
+}
+
+// recordUnknownProvider keeps metrics.UnknownProviderNodes up to date with the scheme of a node no provider is enabled
+// for, an empty scheme meaning the node is no longer skipped for that reason
+func (r *ReconcileNode) recordUnknownProvider(nodeName string, scheme string) {
+	recordNode(&r.unknownProviderNodes, metrics.UnknownProviderNodes, nodeName, scheme)
+}
+
+// recordNode moves a node to the label of the gauge it is now counted under, an empty label meaning it is no longer
+// counted. The nodes holds the label each node is counted under, so every node is counted once.
+func recordNode(nodes *sync.Map, gauge *prometheus.GaugeVec, nodeName string, label string) {
+	previous, found := nodes.Load(nodeName)
+
+	if found && previous != label {
+		nodes.Delete(nodeName)
+		gauge.WithLabelValues(previous.(string)).Dec()
+
+		found = false
+	}
+
+	if label != "" && !found {
+		nodes.Store(nodeName, label)
+		gauge.WithLabelValues(label).Inc()
+	}
+}
+
+// appliedFingerprint identifies the tags applied to the instance of a node, along with their conflict policies as they
+// decide which of the existing tags are overwritten
+func appliedFingerprint(node *corev1.Node, tags map[string]string, conflictPolicies tagging.ConflictPolicies) string {
+	policies := map[string]string{}
+	for key := range tags {
+		policies[key] = string(conflictPolicies.For(key))
+	}
+
+	return node.Spec.ProviderID + "\n" + tagging.Fingerprint(tags) + "\n" + tagging.Fingerprint(policies)
+}
+
+// desiredTags computes the tags the instance of the node should have, along with their conflict policies. The tags
+// provided through the command line are applied first, followed by every NodeTagPolicy matching the node in
+// alphabetical order of name and finally by the tags annotation of the node, so when two sources set the same key
+// the last one wins. Tags without a conflict policy of their own use the one from the command line.
+func (r *ReconcileNode) desiredTags(node *corev1.Node) (map[string]string, tagging.ConflictPolicies, error) {
+	policies := &v1alpha1.NodeTagPolicyList{}
+
+	err := r.client.List(context.TODO(), policies)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(policies.Items, func(i, j int) bool {
+		return policies.Items[i].Name < policies.Items[j].Name
+	})
+
+	defaultConflictPolicy := tagging.ConflictPolicy(flags.ConflictPolicy)
+	tags := map[string]string{}
+	conflictPolicies := tagging.ConflictPolicies{}
+
+	for key, value := range flags.InstanceTags {
+		tags[key] = value
+		conflictPolicies[key] = defaultConflictPolicy
+	}
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+
+		if !policyMatchesNode(policy, node) {
+			log.V(constants.DebugLogVerbosity).Info("Node does not match policy. Skipping policy",
+				"Node.Name", node.Name, "NodeTagPolicy.Name", policy.Name)
+			continue
+		}
+
+		conflictPolicy := defaultConflictPolicy
+
+		if policy.Spec.ConflictPolicy != "" {
+			conflictPolicy, err = tagging.ParseConflictPolicy(policy.Spec.ConflictPolicy)
+			if err != nil {
+				return nil, nil, pkgerrors.Wrapf(err, "invalid NodeTagPolicy %s", policy.Name)
+			}
+		}
+
+		for key, value := range policy.Spec.Tags {
+			tags[key] = value
+			conflictPolicies[key] = conflictPolicy
+		}
+	}
+
+	if annotation, found := node.Annotations[constants.TagsAnnotation]; found {
+		annotationTags, err := tagging.ParseTagsAnnotation(annotation)
+		if err != nil {
+			return nil, nil, pkgerrors.Wrapf(err, "invalid %s annotation on node %s", constants.TagsAnnotation, node.Name)
+		}
+
+		for key, value := range annotationTags {
+			tags[key] = value
+			conflictPolicies[key] = defaultConflictPolicy
+		}
+	}
+
+	return tags, conflictPolicies, nil
+}
+
+func isSkippedNode(node *corev1.Node) bool {
+	skip, err := strconv.ParseBool(node.Annotations[constants.SkipAnnotation])
+	return err == nil && skip
 }
@@ -1,20 +1,38 @@
 package node
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	"github.com/ouzi-dev/node-tagger/pkg/apis/nodetagger/v1alpha1"
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
 	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/metrics"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -25,10 +43,16 @@ const (
 )
 
 type testReconcileItem struct {
-	testName          string
-	resource          *corev1.Node
-	expectedError     error
-	shouldTagInstance bool
+	testName             string
+	resource             *corev1.Node
+	policies             []runtime.Object
+	flagTags             map[string]string
+	expectedTags         map[string]string
+	expectedPolicies     tagging.ConflictPolicies
+	expectedEvent        string
+	expectedError        error
+	expectedErrorMessage string
+	shouldTagInstance    bool
 }
 
 var inputTags = map[string]string{
@@ -36,9 +60,53 @@ var inputTags = map[string]string{
 	"tag2": "value2",
 }
 
+var awsNode = &corev1.Node{
+	TypeMeta: metav1.TypeMeta{
+		Kind:       NodeKind,
+		APIVersion: NodeAPIVersion,
+	},
+	ObjectMeta: metav1.ObjectMeta{
+		Name: name,
+	},
+	Spec: corev1.NodeSpec{
+		ProviderID: "aws:///az/i-instance-id",
+	},
+}
+
+func newAnnotatedAwsNode(annotations map[string]string) *corev1.Node {
+	node := awsNode.DeepCopy()
+	node.Annotations = annotations
+
+	return node
+}
+
+func newPolicy(policyName string, tags map[string]string) *v1alpha1.NodeTagPolicy {
+	return &v1alpha1.NodeTagPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: policyName,
+		},
+		Spec: v1alpha1.NodeTagPolicySpec{
+			Tags: tags,
+		},
+	}
+}
+
 var tests = []testReconcileItem{
 	{
-		testName: "non-aws node",
+		testName: "node without provider id",
+		resource: &corev1.Node{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       NodeKind,
+				APIVersion: NodeAPIVersion,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+		shouldTagInstance: false,
+	},
+	{
+		testName: "node of a provider that is not enabled",
 		resource: &corev1.Node{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       NodeKind,
@@ -86,6 +154,231 @@ var tests = []testReconcileItem{
 		},
 		shouldTagInstance: true,
 	},
+	{
+		testName:          "aws node without any tags",
+		resource:          awsNode,
+		flagTags:          map[string]string{},
+		expectedTags:      map[string]string{},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node tags from policy",
+		resource: awsNode,
+		flagTags: map[string]string{},
+		policies: []runtime.Object{
+			newPolicy("policy", inputTags),
+		},
+		expectedTags:      inputTags,
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node policies override flag tags in name order",
+		resource: awsNode,
+		flagTags: map[string]string{
+			"tag1": "flag",
+			"tag3": "flag",
+		},
+		policies: []runtime.Object{
+			newPolicy("b-policy", map[string]string{"tag2": "b"}),
+			newPolicy("a-policy", map[string]string{"tag1": "a", "tag2": "a"}),
+		},
+		expectedTags: map[string]string{
+			"tag1": "a",
+			"tag2": "b",
+			"tag3": "flag",
+		},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node only matching policies apply",
+		resource: awsNode,
+		flagTags: map[string]string{},
+		policies: []runtime.Object{
+			newPolicy("matching", inputTags),
+			&v1alpha1.NodeTagPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "not-matching",
+				},
+				Spec: v1alpha1.NodeTagPolicySpec{
+					Tags: map[string]string{"tag3": "value3"},
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"pool": "gpu"},
+					},
+				},
+			},
+		},
+		expectedTags:      inputTags,
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node not matching any policy",
+		resource: awsNode,
+		flagTags: map[string]string{},
+		policies: []runtime.Object{
+			&v1alpha1.NodeTagPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "not-matching",
+				},
+				Spec: v1alpha1.NodeTagPolicySpec{
+					Tags:          inputTags,
+					NodeNameRegex: "^gpu-",
+				},
+			},
+		},
+		expectedTags:      map[string]string{},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node templated tags",
+		resource: awsNode,
+		flagTags: map[string]string{
+			"node": "{{ .Name | lower }}",
+		},
+		expectedTags: map[string]string{
+			"node": "node",
+		},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node templated tags referencing missing data",
+		resource: awsNode,
+		flagTags: map[string]string{
+			"zone": `{{ .Labels "topology.kubernetes.io/zone" }}`,
+		},
+		expectedErrorMessage: `failed to render template for tag "zone": template: zone:1:3: executing "zone" ` +
+			`at <.Labels>: error calling Labels: node Node has no label "topology.kubernetes.io/zone"`,
+		shouldTagInstance: false,
+	},
+	{
+		testName: "aws node with skip annotation",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.SkipAnnotation: "true",
+		}),
+		shouldTagInstance: false,
+	},
+	{
+		testName: "aws node with skip annotation set to false",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.SkipAnnotation: "false",
+		}),
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node annotation tags override flag and policy tags",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.TagsAnnotation: `{"tag2": "annotation", "tag4": "{{ .Name }}"}`,
+		}),
+		flagTags: map[string]string{
+			"tag1": "flag",
+			"tag2": "flag",
+			"tag3": "flag",
+		},
+		policies: []runtime.Object{
+			newPolicy("policy", map[string]string{"tag2": "policy", "tag3": "policy"}),
+		},
+		expectedTags: map[string]string{
+			"tag1": "flag",
+			"tag2": "annotation",
+			"tag3": "policy",
+			"tag4": "Node",
+		},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node annotation tags only",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.TagsAnnotation: `{"tag1": "value1", "tag2": "value2"}`,
+		}),
+		flagTags:          map[string]string{},
+		expectedTags:      inputTags,
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node invalid annotation tags",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.TagsAnnotation: "tag1=value1",
+		}),
+		expectedErrorMessage: "invalid node-tagger.ouzi.dev/tags annotation on node Node: tags annotation must be " +
+			"a JSON object of string keys to string values: invalid character 'a' in literal true (expecting 'r')",
+		shouldTagInstance: false,
+	},
+	{
+		testName: "aws node conflict policies from flag and policies",
+		resource: newAnnotatedAwsNode(map[string]string{
+			constants.TagsAnnotation: `{"tag4": "annotation"}`,
+		}),
+		flagTags: map[string]string{
+			"tag1": "flag",
+			"tag2": "flag",
+		},
+		policies: []runtime.Object{
+			&v1alpha1.NodeTagPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fail",
+				},
+				Spec: v1alpha1.NodeTagPolicySpec{
+					Tags:           map[string]string{"tag2": "fail", "tag3": "fail"},
+					ConflictPolicy: "fail",
+				},
+			},
+		},
+		expectedTags: map[string]string{
+			"tag1": "flag",
+			"tag2": "fail",
+			"tag3": "fail",
+			"tag4": "annotation",
+		},
+		expectedPolicies: tagging.ConflictPolicies{
+			"tag1": tagging.ConflictPolicyOverwrite,
+			"tag2": tagging.ConflictPolicyFail,
+			"tag3": tagging.ConflictPolicyFail,
+			"tag4": tagging.ConflictPolicyOverwrite,
+		},
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node conflicting tags",
+		resource: awsNode,
+		expectedError: &tagging.ConflictError{
+			Conflicts: []tagging.Conflict{
+				{Key: "tag1", ExistingValue: "terraform", DesiredValue: "value1"},
+			},
+		},
+		expectedEvent: `Warning TagConflict conflicting tags: tag1 (existing value "terraform", ` +
+			`desired value "value1")`,
+		shouldTagInstance: true,
+	},
+	{
+		testName: "aws node templated tags too long",
+		resource: awsNode,
+		flagTags: map[string]string{
+			"aws:tag": "value",
+			"tag":     `{{ .Name | replace "Node" "` + strings.Repeat("a", 300) + `" }}`,
+		},
+		expectedErrorMessage: `node Node: invalid tags: key "aws:tag" uses the reserved aws: prefix; ` +
+			`value of key "tag" is 300 characters long, the maximum is 256`,
+		expectedEvent: `Warning InvalidTags node Node: invalid tags: key "aws:tag" uses the reserved aws: prefix; ` +
+			`value of key "tag" is 300 characters long, the maximum is 256`,
+		shouldTagInstance: false,
+	},
+	{
+		testName: "aws node invalid policy conflict policy",
+		resource: awsNode,
+		policies: []runtime.Object{
+			&v1alpha1.NodeTagPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "invalid",
+				},
+				Spec: v1alpha1.NodeTagPolicySpec{
+					Tags:           inputTags,
+					ConflictPolicy: "keep",
+				},
+			},
+		},
+		expectedErrorMessage: `invalid NodeTagPolicy invalid: invalid conflict policy "keep", must be one of ` +
+			`overwrite, keep-existing or fail`,
+		shouldTagInstance: false,
+	},
 }
 
 func TestReconcileNode_Reconcile(t *testing.T) {
@@ -94,6 +387,15 @@ func TestReconcileNode_Reconcile(t *testing.T) {
 		testData := testData
 		t.Run(testData.testName, func(t *testing.T) {
 			flags.InstanceTags = inputTags
+			flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+			if testData.flagTags != nil {
+				flags.InstanceTags = testData.flagTags
+			}
+
+			expectedTags := inputTags
+			if testData.expectedTags != nil {
+				expectedTags = testData.expectedTags
+			}
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
@@ -101,20 +403,27 @@ func TestReconcileNode_Reconcile(t *testing.T) {
 
 			// Register operator types with the runtime scheme.
 			s := scheme.Scheme
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.NodeTagPolicy{}, &v1alpha1.NodeTagPolicyList{})
 
 			// Objects to track in the fake client.
 			objs := []runtime.Object{
 				testData.resource,
 			}
+			objs = append(objs, testData.policies...)
 
 			// Create a fake client to mock API calls.
 			cl := fake.NewFakeClientWithScheme(s, objs...)
 
 			// Create a ReconcileNode object with the scheme and fake client.
+			recorder := record.NewFakeRecorder(1)
+			providers := provider.NewRegistry()
+			providers.Register("aws", mockNodeTagger)
+
 			r := &ReconcileNode{
-				client:     cl,
-				scheme:     s,
-				nodeTagger: mockNodeTagger,
+				client:    cl,
+				scheme:    s,
+				recorder:  recorder,
+				providers: providers,
 			}
 
 			// Mock request to simulate Reconcile() being called on an event for a
@@ -126,19 +435,459 @@ func TestReconcileNode_Reconcile(t *testing.T) {
 				},
 			}
 
+			var expectedPolicies interface{} = gomock.Any()
+			if testData.expectedPolicies != nil {
+				expectedPolicies = testData.expectedPolicies
+			}
+
 			numberOfTimesToTagInstance := 0
 			if testData.shouldTagInstance {
 				numberOfTimesToTagInstance = 1
 			}
 
 			mockNodeTagger.
-				EXPECT().EnsureInstanceNodeHasTags(testData.resource, inputTags).
-				Return(testData.expectedError).
+				EXPECT().EnsureInstanceNodeHasTags(testData.resource, expectedTags, expectedPolicies).
+				Return(provider.TagResult{}, testData.expectedError).
 				Times(numberOfTimesToTagInstance)
 
 			_, err := r.Reconcile(req)
 
+			if testData.expectedEvent != "" {
+				assert.Equal(t, testData.expectedEvent, <-recorder.Events)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+
+			if testData.expectedErrorMessage != "" {
+				assert.EqualError(t, err, testData.expectedErrorMessage)
+				return
+			}
+
 			assert.Equal(t, testData.expectedError, err)
 		})
 	}
 }
+
+func TestReconcileNode_Reconcile_CountsNodesOfProvidersNotEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gceNode := awsNode.DeepCopy()
+	gceNode.Spec.ProviderID = "gce://project/europe-west1-b/instance"
+
+	providers := provider.NewRegistry()
+	providers.Register("aws", mocks.NewMockNodeTagger(ctrl))
+
+	r := &ReconcileNode{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, gceNode),
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(1),
+		providers: providers,
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+	skippedBefore := testutil.ToFloat64(metrics.UnknownProviderNodes.WithLabelValues("gce"))
+
+	// The node is counted once however many times it is reconciled
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, skippedBefore+1, testutil.ToFloat64(metrics.UnknownProviderNodes.WithLabelValues("gce")))
+	}
+
+	err := r.client.Delete(context.TODO(), gceNode.DeepCopy())
+	assert.NoError(t, err)
+
+	_, err = r.Reconcile(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, skippedBefore, testutil.ToFloat64(metrics.UnknownProviderNodes.WithLabelValues("gce")))
+}
+
+func TestReconcileNode_Reconcile_SkipsNodesOfUnknownRegion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flags.InstanceTags = inputTags
+
+	mockNodeTagger := mocks.NewMockNodeTagger(ctrl)
+	mockNodeTagger.
+		EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).
+		Return(provider.TagResult{}, &aws.RegionError{Node: name}).
+		Times(1)
+
+	providers := provider.NewRegistry()
+	providers.Register("aws", mockNodeTagger)
+
+	recorder := record.NewFakeRecorder(1)
+	r := &ReconcileNode{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode),
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+		providers: providers,
+	}
+
+	skippedBefore := testutil.ToFloat64(metrics.UnknownRegionNodes)
+
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+	assert.Equal(t, "Warning UnknownRegion cannot determine the region of node Node from its ProviderID or its "+
+		"topology.kubernetes.io/region label, and no default region is configured", <-recorder.Events)
+	assert.Equal(t, skippedBefore+1, testutil.ToFloat64(metrics.UnknownRegionNodes))
+}
+
+func TestNodeChanged(t *testing.T) {
+	node := awsNode.DeepCopy()
+	node.ResourceVersion = "1"
+	node.Labels = map[string]string{"team": "platform"}
+	node.Annotations = map[string]string{constants.TagsAnnotation: `{"tag1": "value1"}`}
+
+	heartbeat := node.DeepCopy()
+	heartbeat.ResourceVersion = "2"
+	heartbeat.Status.Conditions = []corev1.NodeCondition{
+		{
+			Type:              corev1.NodeReady,
+			Status:            corev1.ConditionTrue,
+			LastHeartbeatTime: metav1.Now(),
+		},
+	}
+
+	newStatus := node.DeepCopy()
+	newStatus.ResourceVersion = "2"
+	newStatus.Status.Images = []corev1.ContainerImage{{Names: []string{"busybox"}}}
+	newStatus.Status.VolumesInUse = []corev1.UniqueVolumeName{"kubernetes.io/aws-ebs/vol-1"}
+
+	cordoned := node.DeepCopy()
+	cordoned.ResourceVersion = "2"
+	cordoned.Spec.Unschedulable = true
+
+	uninitialised := node.DeepCopy()
+	uninitialised.Spec.ProviderID = ""
+	uninitialised.ResourceVersion = "0"
+
+	relabeled := node.DeepCopy()
+	relabeled.ResourceVersion = "2"
+	relabeled.Labels["team"] = "machine-learning"
+
+	resynced := node.DeepCopy()
+	resynced.ResourceVersion = "2"
+	resynced.Annotations[constants.ResyncAnnotation] = "1"
+
+	tests := []struct {
+		testName string
+		oldNode  runtime.Object
+		newNode  runtime.Object
+		expected bool
+	}{
+		{testName: "status heartbeat", oldNode: node, newNode: heartbeat, expected: false},
+		{testName: "status changed", oldNode: node, newNode: newStatus, expected: false},
+		{testName: "node cordoned", oldNode: node, newNode: cordoned, expected: false},
+		{testName: "ProviderID set", oldNode: uninitialised, newNode: node, expected: true},
+		{testName: "labels changed", oldNode: node, newNode: relabeled, expected: true},
+		{testName: "annotations changed", oldNode: node, newNode: resynced, expected: true},
+		{testName: "periodic resync", oldNode: node, newNode: node.DeepCopy(), expected: true},
+		{testName: "not a node", oldNode: node, newNode: &v1alpha1.NodeTagPolicy{}, expected: false},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			oldMeta, _ := meta.Accessor(testData.oldNode)
+			newMeta, _ := meta.Accessor(testData.newNode)
+
+			assert.Equal(t, testData.expected, nodeChanged(event.UpdateEvent{
+				MetaOld:   oldMeta,
+				ObjectOld: testData.oldNode,
+				MetaNew:   newMeta,
+				ObjectNew: testData.newNode,
+			}))
+		})
+	}
+}
+
+func TestReconcileNode_Reconcile_ReportsTagDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flags.InstanceTags = inputTags
+	flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+
+	tagged := provider.NewTagResult([]string{"tag1", "tag2"}, nil)
+	drifted := provider.NewTagResult([]string{"tag2"}, []string{"tag3"})
+
+	// The first reconcile tags the instance, the second one finds it tagged and the third one finds its tags changed
+	mockNodeTagger := mocks.NewMockNodeTagger(ctrl)
+	gomock.InOrder(
+		mockNodeTagger.EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).Return(tagged, nil),
+		mockNodeTagger.EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).Return(provider.TagResult{}, nil),
+		mockNodeTagger.EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).Return(drifted, nil),
+	)
+
+	providers := provider.NewRegistry()
+	providers.Register("aws", mockNodeTagger)
+
+	recorder := record.NewFakeRecorder(1)
+	r := &ReconcileNode{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode),
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+		providers: providers,
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Normal Tagged Applied tags: tag1, tag2", <-recorder.Events)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Warning TagDrift Restored tags changed outside node-tagger: tag2, tag3", <-recorder.Events)
+
+	// Tags applied because the desired ones changed are not drift
+	flags.InstanceTags = map[string]string{"tag1": "changed"}
+
+	mockNodeTagger.
+		EXPECT().EnsureInstanceNodeHasTags(awsNode, flags.InstanceTags, gomock.Any()).
+		Return(provider.NewTagResult([]string{"tag1"}, []string{"tag2"}), nil)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Normal Tagged Applied tags: tag1. Removed tags: tag2", <-recorder.Events)
+}
+
+func TestReconcileNode_Reconcile_CountsTaggingOutcomes(t *testing.T) {
+	tests := []struct {
+		testName        string
+		result          provider.TagResult
+		err             error
+		expectedOutcome string
+		expectedEvents  []string
+		compliant       bool
+	}{
+		{
+			testName:        "instance already tagged",
+			expectedOutcome: "already-tagged",
+			compliant:       true,
+		},
+		{
+			testName:        "instance tagged",
+			result:          provider.NewTagResult([]string{"tag1"}, nil),
+			expectedOutcome: "tagged",
+			// Tagging the instance again with the same tags means they were changed in between
+			expectedEvents: []string{
+				"Normal Tagged Applied tags: tag1",
+				"Warning TagDrift Restored tags changed outside node-tagger: tag1",
+			},
+			compliant: true,
+		},
+		{
+			testName:        "instance not found",
+			err:             &aws.InstanceNotFoundError{Lookups: "private dns: " + name},
+			expectedOutcome: "not-found",
+			expectedEvents:  []string{"Warning InstanceNotFound No instances found for the node with private dns: Node"},
+		},
+		{
+			testName:        "multiple instances found",
+			err:             &aws.MultipleInstancesError{Lookup: "private dns: " + name},
+			expectedOutcome: "ambiguous",
+			expectedEvents: []string{
+				"Warning MultipleInstances More than one instances found with private dns: Node. " +
+					"Cannot proceed with tagging",
+			},
+		},
+		{
+			testName:        "permission denied",
+			err:             &provider.PermissionDeniedError{Err: errors.New("not authorized")},
+			expectedOutcome: "error",
+			expectedEvents:  []string{"Warning PermissionDenied not authorized"},
+		},
+		{
+			testName:        "tagging fails",
+			err:             errors.New("error"),
+			expectedOutcome: "error",
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			flags.InstanceTags = inputTags
+
+			mockNodeTagger := mocks.NewMockNodeTagger(ctrl)
+			mockNodeTagger.
+				EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).
+				Return(testData.result, testData.err).
+				Times(2)
+
+			providers := provider.NewRegistry()
+			providers.Register("aws", mockNodeTagger)
+
+			// Repeated events are only recorded once
+			recorder := record.NewFakeRecorder(2)
+			r := &ReconcileNode{
+				client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode),
+				scheme:    scheme.Scheme,
+				recorder:  newDedupingRecorder(recorder, time.Hour),
+				providers: providers,
+			}
+
+			attempts := metrics.TaggingAttempts.WithLabelValues("aws", testData.expectedOutcome)
+			attemptsBefore := testutil.ToFloat64(attempts)
+			nonCompliantBefore := testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws"))
+
+			expectedNonCompliant := nonCompliantBefore
+			if !testData.compliant {
+				expectedNonCompliant++
+			}
+
+			// A node failing again is still counted once as non compliant
+			for i := 0; i < 2; i++ {
+				_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+
+				assert.Equal(t, testData.err, err)
+				assert.Equal(t, expectedNonCompliant, testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws")))
+			}
+
+			assert.Equal(t, attemptsBefore+2, testutil.ToFloat64(attempts))
+
+			for _, expectedEvent := range testData.expectedEvents {
+				assert.Equal(t, expectedEvent, <-recorder.Events)
+			}
+
+			assert.Empty(t, recorder.Events)
+
+			// Deleted nodes no longer need to be tagged
+			r.client = fake.NewFakeClientWithScheme(scheme.Scheme)
+
+			_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, nonCompliantBefore, testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws")))
+		})
+	}
+}
+
+func TestReconcileNode_Reconcile_ForgetsDeletedNodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flags.InstanceTags = inputTags
+
+	mockNodeTagger := mocks.NewMockNodeTagger(ctrl)
+	mockNodeTagger.
+		EXPECT().EnsureInstanceNodeHasTags(awsNode, inputTags, gomock.Any()).
+		Return(provider.TagResult{}, errors.New("error"))
+
+	providers := provider.NewRegistry()
+	providers.Register("aws", mockNodeTagger)
+
+	r := &ReconcileNode{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode.DeepCopy()),
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(1),
+		providers: providers,
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+	nonCompliantBefore := testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws"))
+
+	_, err := r.Reconcile(req)
+	assert.EqualError(t, err, "error")
+	assert.Equal(t, nonCompliantBefore+1, testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws")))
+
+	deleted := awsNode.DeepCopy()
+	err = r.client.Delete(context.TODO(), deleted)
+	assert.NoError(t, err)
+
+	// The deletion of the node is reconciled
+	assert.True(t, nodePredicates.Delete(event.DeleteEvent{Meta: deleted, Object: deleted}))
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, nonCompliantBefore, testutil.ToFloat64(metrics.NonCompliantNodes.WithLabelValues("aws")))
+
+	_, found := r.nonCompliantNodes.Load(name)
+	assert.False(t, found)
+}
+
+func TestReconcileNode_Reconcile_BatchesConcurrentReconciles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flags.InstanceTags = inputTags
+
+	instanceIDs := []string{"i-one", "i-two", "i-three"}
+	nodes := []runtime.Object{}
+
+	for _, instanceID := range instanceIDs {
+		node := awsNode.DeepCopy()
+		node.Name = instanceID
+		node.Spec.ProviderID = "aws:///eu-west-1a/" + instanceID
+		nodes = append(nodes, node)
+	}
+
+	// The nodes reconciled at the same time are looked up with one call and tagged with another
+	mockEc2Client := mocks.NewMockEC2API(ctrl)
+	mockEc2Client.
+		EXPECT().DescribeInstancesPages(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+			output := &ec2.DescribeInstancesOutput{}
+			for _, instanceID := range input.Filters[0].Values {
+				output.Reservations = append(output.Reservations, &ec2.Reservation{
+					Instances: []*ec2.Instance{{InstanceId: instanceID}},
+				})
+			}
+
+			fn(output, true)
+
+			return nil
+		})
+	mockEc2Client.
+		EXPECT().CreateTags(gomock.Any()).
+		DoAndReturn(func(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+			assert.ElementsMatch(t, instanceIDs, awssdk.StringValueSlice(input.Resources))
+			return &ec2.CreateTagsOutput{}, nil
+		})
+
+	providers := provider.NewRegistry()
+	providers.Register("aws", aws.NewRegionalNodeTagger(func(region string) (ec2iface.EC2API, error) {
+		return mockEc2Client, nil
+	}, aws.NodeInstanceTaggerOptions{BatchWindow: 200 * time.Millisecond}))
+
+	scheme.Scheme.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.NodeTagPolicy{}, &v1alpha1.NodeTagPolicyList{})
+
+	r := &ReconcileNode{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, nodes...),
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(len(instanceIDs)),
+		providers: providers,
+	}
+
+	var wg sync.WaitGroup
+
+	for _, instanceID := range instanceIDs {
+		wg.Add(1)
+
+		go func(instanceID string) {
+			defer wg.Done()
+
+			_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: instanceID}})
+			assert.NoError(t, err)
+		}(instanceID)
+	}
+
+	wg.Wait()
+}
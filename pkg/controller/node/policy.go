@@ -0,0 +1,82 @@
+package node
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/ouzi-dev/node-tagger/pkg/apis/nodetagger/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// policyMapper maps a NodeTagPolicy to the Nodes whose desired tags it contributes to
+type policyMapper struct {
+	client client.Client
+}
+
+// Map is called for both the old and the new version of an updated policy, so nodes that stop matching it are
+// requeued as well
+func (m *policyMapper) Map(obj handler.MapObject) []reconcile.Request {
+	policy, ok := obj.Object.(*v1alpha1.NodeTagPolicy)
+	if !ok {
+		return nil
+	}
+
+	nodes := &corev1.NodeList{}
+
+	err := m.client.List(context.TODO(), nodes)
+	if err != nil {
+		log.Error(err, "Failed to list nodes for policy", "NodeTagPolicy.Name", policy.Name)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for i := range nodes.Items {
+		if !policyMatchesNode(policy, &nodes.Items[i]) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: nodes.Items[i].Name},
+		})
+	}
+
+	return requests
+}
+
+// policyMatchesNode returns whether the node selector and the node name regex of the policy both match the node.
+// Policies with an invalid selector or regex do not match any node.
+func policyMatchesNode(policy *v1alpha1.NodeTagPolicy, node *corev1.Node) bool {
+	if policy.Spec.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeSelector)
+		if err != nil {
+			log.Error(err, "Invalid node selector in policy", "NodeTagPolicy.Name", policy.Name)
+			return false
+		}
+
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+
+	if policy.Spec.NodeNameRegex != "" {
+		nameRegex, err := regexp.Compile(policy.Spec.NodeNameRegex)
+		if err != nil {
+			log.Error(err, "Invalid node name regex in policy", "NodeTagPolicy.Name", policy.Name)
+			return false
+		}
+
+		if !nameRegex.MatchString(node.Name) {
+			return false
+		}
+	}
+
+	return true
+}
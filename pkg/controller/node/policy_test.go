@@ -0,0 +1,142 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/ouzi-dev/node-tagger/pkg/apis/nodetagger/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var gpuNode = &corev1.Node{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "gpu-node",
+		Labels: map[string]string{
+			"pool": "gpu",
+		},
+	},
+}
+
+func TestPolicyMatchesNode(t *testing.T) {
+	tests := []struct {
+		testName string
+		spec     v1alpha1.NodeTagPolicySpec
+		matches  bool
+	}{
+		{
+			testName: "no selector nor regex",
+			spec:     v1alpha1.NodeTagPolicySpec{},
+			matches:  true,
+		},
+		{
+			testName: "empty selector",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeSelector: &metav1.LabelSelector{},
+			},
+			matches: true,
+		},
+		{
+			testName: "matching selector",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"pool": "gpu"},
+				},
+			},
+			matches: true,
+		},
+		{
+			testName: "not matching selector",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "pool", Operator: metav1.LabelSelectorOpIn, Values: []string{"spot", "system"}},
+					},
+				},
+			},
+			matches: false,
+		},
+		{
+			testName: "invalid selector",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "pool", Operator: "Invalid"},
+					},
+				},
+			},
+			matches: false,
+		},
+		{
+			testName: "matching regex",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeNameRegex: "^gpu-",
+			},
+			matches: true,
+		},
+		{
+			testName: "not matching regex",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeNameRegex: "^spot-",
+			},
+			matches: false,
+		},
+		{
+			testName: "invalid regex",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeNameRegex: "(",
+			},
+			matches: false,
+		},
+		{
+			testName: "matching selector and not matching regex",
+			spec: v1alpha1.NodeTagPolicySpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"pool": "gpu"},
+				},
+				NodeNameRegex: "^spot-",
+			},
+			matches: false,
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			policy := &v1alpha1.NodeTagPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "policy",
+				},
+				Spec: testData.spec,
+			}
+
+			assert.Equal(t, testData.matches, policyMatchesNode(policy, gpuNode))
+		})
+	}
+}
+
+func TestPolicyMapper_Map(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.NodeTagPolicy{}, &v1alpha1.NodeTagPolicyList{})
+
+	policy := newPolicy("policy", inputTags)
+	policy.Spec.NodeSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"pool": "gpu"},
+	}
+
+	cl := fake.NewFakeClientWithScheme(s, awsNode, gpuNode, policy)
+	mapper := &policyMapper{client: cl}
+
+	requests := mapper.Map(handler.MapObject{Meta: policy, Object: policy})
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: gpuNode.Name}},
+	}, requests)
+}
@@ -0,0 +1,125 @@
+package node
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/azure"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/gce"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+// providerFactories create the NodeTagger of every supported cloud provider from its command line configuration. They
+// are keyed by the name used in --providers, which is also the scheme of the ProviderIDs of the nodes running on it.
+var providerFactories = map[string]func() (provider.NodeTagger, error){
+	"aws":   newAwsNodeTagger,
+	"gce":   newGceNodeTagger,
+	"azure": newAzureNodeTagger,
+}
+
+// awsBatchWindow is the window of the batching EC2 clients. A batch only gathers the nodes reconciled at the same
+// time, so batching is disabled when the nodes are reconciled one at a time, as the window would only delay every call.
+func awsBatchWindow() time.Duration {
+	if flags.MaxConcurrentReconciles <= 1 {
+		return 0
+	}
+
+	return flags.AWSBatchWindow
+}
+
+// newAwsNodeTagger tags every node through the EC2 API of its region, with the credentials configured in the
+// environment
+func newAwsNodeTagger() (provider.NodeTagger, error) {
+	awsSession, err := aws.GetAwsSessionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	options := aws.NodeInstanceTaggerOptions{
+		TagRootVolumes:       flags.TagRootVolumes,
+		TagDataVolumes:       flags.TagDataVolumes,
+		TagNetworkInterfaces: flags.TagNetworkInterfaces,
+		BatchWindow:          awsBatchWindow(),
+		TagCacheTTL:          flags.AWSTagCacheTTL,
+		DefaultRegion:        awssdk.StringValue(awsSession.Config.Region),
+	}
+
+	nodeTagger := aws.NewRegionalNodeTagger(aws.NewSessionEC2ClientFactory(awsSession), options)
+
+	if flags.AssumeRoleConfig == "" {
+		return nodeTagger, nil
+	}
+
+	// The nodes of other accounts are tagged with the roles mapped to them, assumed with the credentials of the session
+	mappings, err := aws.LoadAssumeRoleMappings(flags.AssumeRoleConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]aws.Account, 0, len(mappings))
+	for _, mapping := range mappings {
+		accounts = append(accounts, aws.NewAssumeRoleAccount(awsSession, mapping, options))
+	}
+
+	return aws.NewAccountNodeTagger(nodeTagger, accounts)
+}
+
+// newAzureNodeTagger authenticates with the service principal or the managed identity configured in the environment
+func newAzureNodeTagger() (provider.NodeTagger, error) {
+	httpClient, err := azure.GetAzureHTTPClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return azure.NewVMTagger(httpClient, azure.ManagementEndpoint), nil
+}
+
+// newGceNodeTagger authenticates with the application default credentials, such as the service account of the
+// instance or workload identity
+func newGceNodeTagger() (provider.NodeTagger, error) {
+	httpClient, err := google.DefaultClient(context.Background(), gce.ComputeScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return gce.NewInstanceTagger(httpClient, gce.ComputeEndpoint), nil
+}
+
+// newProviderRegistry creates the NodeTagger of every provider enabled on the command line
+func newProviderRegistry(names []string) (*provider.Registry, error) {
+	registry := provider.NewRegistry()
+
+	for _, name := range names {
+		factory, found := providerFactories[name]
+		if !found {
+			return nil, pkgerrors.Errorf("unknown provider %q, must be one of %s", name, supportedProviders())
+		}
+
+		nodeTagger, err := factory()
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "failed to configure provider %s", name)
+		}
+
+		registry.Register(name, nodeTagger)
+	}
+
+	return registry, nil
+}
+
+func supportedProviders() string {
+	names := make([]string, 0, len(providerFactories))
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
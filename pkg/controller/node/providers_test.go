@@ -0,0 +1,49 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProviderRegistry_ReturnsError_If_ProviderIsUnknown(t *testing.T) {
+	_, err := newProviderRegistry([]string{"openstack"})
+
+	assert.EqualError(t, err, `unknown provider "openstack", must be one of aws, azure, gce`)
+}
+
+func TestNewProviderRegistry_ReturnsEmptyRegistry_If_NoProviderIsEnabled(t *testing.T) {
+	registry, err := newProviderRegistry([]string{})
+
+	assert.NoError(t, err)
+
+	_, found := registry.Get("aws")
+	assert.False(t, found)
+}
+
+func TestAwsBatchWindow(t *testing.T) {
+	tests := []struct {
+		testName                string
+		maxConcurrentReconciles int
+		batchWindow             time.Duration
+		expected                time.Duration
+	}{
+		{testName: "nodes reconciled one at a time", maxConcurrentReconciles: 1, batchWindow: time.Second, expected: 0},
+		{testName: "nodes reconciled concurrently", maxConcurrentReconciles: 10, batchWindow: time.Second,
+			expected: time.Second},
+		{testName: "batching disabled", maxConcurrentReconciles: 10, batchWindow: 0, expected: 0},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			flags.MaxConcurrentReconciles = testData.maxConcurrentReconciles
+			flags.AWSBatchWindow = testData.batchWindow
+
+			assert.Equal(t, testData.expected, awsBatchWindow())
+		})
+	}
+}
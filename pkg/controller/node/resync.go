@@ -0,0 +1,70 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// nodeResyncer reconciles every node of an enabled provider periodically, so tags changed or deleted outside
+// node-tagger are restored even when the nodes themselves do not change. The NodeTaggers first forget the tags they
+// remember, so the tags of every instance are read from the cloud provider again. On AWS the lookups of the instances
+// are gathered by the batching EC2 clients into paginated DescribeInstances calls.
+type nodeResyncer struct {
+	client    client.Client
+	providers *provider.Registry
+	period    time.Duration
+	events    chan<- event.GenericEvent
+}
+
+// Start implements manager.Runnable, resyncing the nodes every period until the manager stops
+func (r *nodeResyncer) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			err := r.resync(stop)
+			if err != nil {
+				log.Error(err, "Failed to resync nodes.")
+			}
+		}
+	}
+}
+
+// resync enqueues every node whose provider is enabled
+func (r *nodeResyncer) resync(stop <-chan struct{}) error {
+	nodes := &corev1.NodeList{}
+
+	err := r.client.List(context.TODO(), nodes)
+	if err != nil {
+		return err
+	}
+
+	r.providers.Resync()
+
+	log.Info("Resyncing nodes.", "Nodes", len(nodes.Items))
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		if _, found := r.providers.Get(provider.Scheme(node.Spec.ProviderID)); !found {
+			continue
+		}
+
+		select {
+		case r.events <- event.GenericEvent{Meta: node, Object: node}:
+		case <-stop:
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type resyncingNodeTagger struct {
+	provider.NodeTagger
+	resyncs int
+}
+
+func (r *resyncingNodeTagger) Resync() {
+	r.resyncs++
+}
+
+func TestNodeResyncer_Resync(t *testing.T) {
+	gceNode := awsNode.DeepCopy()
+	gceNode.Name = "gce-node"
+	gceNode.Spec.ProviderID = "gce://project/europe-west1-b/instance"
+
+	uninitialisedNode := awsNode.DeepCopy()
+	uninitialisedNode.Name = "uninitialised-node"
+	uninitialisedNode.Spec.ProviderID = ""
+
+	nodeTagger := &resyncingNodeTagger{}
+	providers := provider.NewRegistry()
+	providers.Register("aws", nodeTagger)
+
+	events := make(chan event.GenericEvent, 3)
+	subject := &nodeResyncer{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode, gceNode, uninitialisedNode),
+		providers: providers,
+		period:    time.Hour,
+		events:    events,
+	}
+
+	err := subject.resync(make(chan struct{}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, nodeTagger.resyncs)
+	assert.Len(t, events, 1)
+
+	resynced := <-events
+	assert.Equal(t, name, resynced.Meta.GetName())
+	assert.IsType(t, &corev1.Node{}, resynced.Object)
+}
+
+func TestNodeResyncer_Resync_StopsWhenTheManagerStops(t *testing.T) {
+	providers := provider.NewRegistry()
+	providers.Register("aws", &resyncingNodeTagger{})
+
+	stop := make(chan struct{})
+	close(stop)
+
+	subject := &nodeResyncer{
+		client:    fake.NewFakeClientWithScheme(scheme.Scheme, awsNode),
+		providers: providers,
+		period:    time.Hour,
+		events:    make(chan event.GenericEvent),
+	}
+
+	assert.NoError(t, subject.resync(stop))
+	assert.NoError(t, subject.Start(stop))
+}
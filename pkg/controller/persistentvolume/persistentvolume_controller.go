@@ -0,0 +1,237 @@
+package persistentvolume
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	pkgerrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_persistentvolume")
+
+// Add creates a new PersistentVolume Controller and adds it to the Manager when tagging persistent volumes is
+// enabled. The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !flags.TagPersistentVolumes {
+		return nil
+	}
+
+	reconciler, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, reconciler)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	awsSession, err := aws.GetAwsSessionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// The volumes can be tagged from the nodes they are attached to as well, which keep their own managed keys tag
+	return &ReconcilePersistentVolume{
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		recorder:       mgr.GetEventRecorderFor("node-tagger"),
+		resourceTagger: aws.NewEC2ResourceTagger(ec2.New(awsSession), constants.VolumeManagedKeysTag),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("persistentvolume-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: flags.MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource PersistentVolume
+	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolume{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for metadata changes to PersistentVolumeClaims and requeue the PersistentVolume bound to them
+	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(mapClaimToVolume),
+	}, predicate.Funcs{
+		UpdateFunc: claimMetadataChanged,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func mapClaimToVolume(obj handler.MapObject) []reconcile.Request {
+	claim, ok := obj.Object.(*corev1.PersistentVolumeClaim)
+	if !ok || claim.Spec.VolumeName == "" {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: claim.Spec.VolumeName}},
+	}
+}
+
+func claimMetadataChanged(e event.UpdateEvent) bool {
+	oldClaim, oldOk := e.ObjectOld.(*corev1.PersistentVolumeClaim)
+	newClaim, newOk := e.ObjectNew.(*corev1.PersistentVolumeClaim)
+
+	if !oldOk || !newOk {
+		return false
+	}
+
+	return oldClaim.Spec.VolumeName != newClaim.Spec.VolumeName ||
+		!labels.Equals(oldClaim.Labels, newClaim.Labels) ||
+		!labels.Equals(oldClaim.Annotations, newClaim.Annotations)
+}
+
+// blank assignment to verify that ReconcilePersistentVolume implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcilePersistentVolume{}
+
+// ReconcilePersistentVolume reconciles a PersistentVolume object
+type ReconcilePersistentVolume struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client         client.Client
+	scheme         *runtime.Scheme
+	recorder       record.EventRecorder
+	resourceTagger aws.ResourceTagger
+	// taggedVolumes holds the volume ID and tags last applied for each PersistentVolume, so the status updates and
+	// the periodic resyncs of a tagged volume make no call to EC2
+	taggedVolumes sync.Map
+}
+
+// Reconcile reads that state of the cluster for a PersistentVolume object and adds tags derived from its claim to
+// the underlying EBS volume if necessary
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcilePersistentVolume) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("PersistentVolume.Name", request.Name)
+	reqLogger.V(constants.DebugLogVerbosity).Info("Reconciling PersistentVolume")
+
+	// Fetch the PersistentVolume instance
+	instance := &corev1.PersistentVolume{}
+
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			r.taggedVolumes.Delete(request.Name)
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// Volume is not backed by EBS so skip it. Return and don't requeue
+	volumeID, isEBSVolume := ebsVolumeID(instance)
+	if !isEBSVolume {
+		reqLogger.V(constants.DebugLogVerbosity).Info("PersistentVolume is not an EBS volume. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	// Volume is not bound so there are no tags to derive. Return and don't requeue
+	if instance.Status.Phase != corev1.VolumeBound || instance.Spec.ClaimRef == nil {
+		reqLogger.V(constants.DebugLogVerbosity).Info("PersistentVolume is not bound. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{
+		Namespace: instance.Spec.ClaimRef.Namespace,
+		Name:      instance.Spec.ClaimRef.Name,
+	}, claim)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.V(constants.DebugLogVerbosity).Info("PersistentVolumeClaim not found. Skipping")
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	namespace := &corev1.Namespace{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: claim.Namespace}, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	tags, err := tagging.ClaimTags(flags.VolumeTags, claim, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	fingerprint := volumeID + "\n" + tagging.Fingerprint(tags)
+	if applied, found := r.taggedVolumes.Load(instance.Name); found && applied == fingerprint {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Volume already tagged.", "Volume.ID", volumeID)
+		return reconcile.Result{}, nil
+	}
+
+	conflictPolicies := tagging.ConflictPolicies{}
+	for key := range tags {
+		conflictPolicies[key] = tagging.ConflictPolicy(flags.ConflictPolicy)
+	}
+
+	err = r.resourceTagger.EnsureResourceHasTags(volumeID, tags, conflictPolicies)
+	if err != nil {
+		var conflictErr *tagging.ConflictError
+		if pkgerrors.As(err, &conflictErr) {
+			r.recorder.Event(instance, corev1.EventTypeWarning, "TagConflict", conflictErr.Error())
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	r.taggedVolumes.Store(instance.Name, fingerprint)
+
+	return reconcile.Result{}, nil
+}
+
+// ebsVolumeID returns the ID of the EBS volume behind the PersistentVolume, for both the in-tree volume plugin,
+// whose IDs can look like aws://<zone>/<volume-id>, and the EBS CSI driver
+func ebsVolumeID(volume *corev1.PersistentVolume) (string, bool) {
+	if volume.Spec.AWSElasticBlockStore != nil {
+		volumeID := volume.Spec.AWSElasticBlockStore.VolumeID
+		return volumeID[strings.LastIndex(volumeID, "/")+1:], true
+	}
+
+	if volume.Spec.CSI != nil && volume.Spec.CSI.Driver == constants.EBSCSIDriver {
+		return volume.Spec.CSI.VolumeHandle, true
+	}
+
+	return "", false
+}
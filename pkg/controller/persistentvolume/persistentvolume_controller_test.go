@@ -0,0 +1,402 @@
+package persistentvolume
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	volumeName    = "pv-1"
+	claimName     = "data"
+	namespaceName = "team-a"
+	volumeID      = "vol-0123456789abcdef0"
+)
+
+type testReconcileItem struct {
+	testName             string
+	resource             *corev1.PersistentVolume
+	claim                *corev1.PersistentVolumeClaim
+	volumeTags           map[string]string
+	expectedVolumeID     string
+	expectedTags         map[string]string
+	expectedEvent        string
+	expectedError        error
+	expectedErrorMessage string
+	shouldTagVolume      bool
+}
+
+var namespace = &corev1.Namespace{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: namespaceName,
+		Labels: map[string]string{
+			"team": "a",
+		},
+		Annotations: map[string]string{
+			constants.TagsAnnotation: `{"cost-center": "1234"}`,
+		},
+	},
+}
+
+var claim = &corev1.PersistentVolumeClaim{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      claimName,
+		Namespace: namespaceName,
+		Labels: map[string]string{
+			"app": "database",
+		},
+	},
+	Spec: corev1.PersistentVolumeClaimSpec{
+		VolumeName: volumeName,
+	},
+}
+
+func newVolume(source corev1.PersistentVolumeSource, phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: volumeName,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: source,
+			ClaimRef: &corev1.ObjectReference{
+				Namespace: namespaceName,
+				Name:      claimName,
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{
+			Phase: phase,
+		},
+	}
+}
+
+var inTreeVolume = newVolume(corev1.PersistentVolumeSource{
+	AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{
+		VolumeID: "aws://eu-west-1a/" + volumeID,
+	},
+}, corev1.VolumeBound)
+
+var csiVolume = newVolume(corev1.PersistentVolumeSource{
+	CSI: &corev1.CSIPersistentVolumeSource{
+		Driver:       constants.EBSCSIDriver,
+		VolumeHandle: volumeID,
+	},
+}, corev1.VolumeBound)
+
+var claimTags = map[string]string{
+	"cost-center": "1234",
+}
+
+var tests = []testReconcileItem{
+	{
+		testName: "volume not backed by ebs",
+		resource: newVolume(corev1.PersistentVolumeSource{
+			CSI: &corev1.CSIPersistentVolumeSource{
+				Driver:       "efs.csi.aws.com",
+				VolumeHandle: "fs-12345678",
+			},
+		}, corev1.VolumeBound),
+		shouldTagVolume: false,
+	},
+	{
+		testName: "volume not bound",
+		resource: newVolume(corev1.PersistentVolumeSource{
+			AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{
+				VolumeID: volumeID,
+			},
+		}, corev1.VolumeAvailable),
+		shouldTagVolume: false,
+	},
+	{
+		testName: "volume with claim not found",
+		resource: inTreeVolume,
+		claim: &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespaceName},
+		},
+		shouldTagVolume: false,
+	},
+	{
+		testName:         "in-tree volume",
+		resource:         inTreeVolume,
+		expectedVolumeID: volumeID,
+		shouldTagVolume:  true,
+	},
+	{
+		testName:         "csi volume",
+		resource:         csiVolume,
+		expectedVolumeID: volumeID,
+		shouldTagVolume:  true,
+	},
+	{
+		testName: "volume tags rendered against the claim",
+		resource: csiVolume,
+		volumeTags: map[string]string{
+			"app":        `{{ .Labels "app" }}`,
+			"team":       `{{ .NamespaceLabels "team" }}`,
+			"pvc":        "{{ .Namespace }}/{{ .Name }}",
+			"kubernetes": "true",
+		},
+		expectedVolumeID: volumeID,
+		expectedTags: map[string]string{
+			"app":         "database",
+			"team":        "a",
+			"pvc":         "team-a/data",
+			"kubernetes":  "true",
+			"cost-center": "1234",
+		},
+		shouldTagVolume: true,
+	},
+	{
+		testName: "volume tags referencing missing data",
+		resource: csiVolume,
+		volumeTags: map[string]string{
+			"owner": `{{ .Labels "owner" }}`,
+		},
+		expectedErrorMessage: `failed to render template for tag "owner": template: owner:1:3: ` +
+			`executing "owner" at <.Labels>: error calling Labels: claim team-a/data has no label "owner"`,
+		shouldTagVolume: false,
+	},
+	{
+		testName:         "volume error tagging",
+		resource:         csiVolume,
+		expectedVolumeID: volumeID,
+		expectedError:    errors.New("error"),
+		shouldTagVolume:  true,
+	},
+	{
+		testName:         "volume conflicting tags",
+		resource:         csiVolume,
+		expectedVolumeID: volumeID,
+		expectedError: &tagging.ConflictError{
+			Conflicts: []tagging.Conflict{
+				{Key: "cost-center", ExistingValue: "0000", DesiredValue: "1234"},
+			},
+		},
+		expectedEvent: `Warning TagConflict conflicting tags: cost-center (existing value "0000", ` +
+			`desired value "1234")`,
+		shouldTagVolume: true,
+	},
+}
+
+func newReconcilePersistentVolume(t *testing.T, ctrl *gomock.Controller,
+	objs ...runtime.Object) (*ReconcilePersistentVolume, *mocks.MockResourceTagger, *record.FakeRecorder) {
+	t.Helper()
+
+	mockResourceTagger := mocks.NewMockResourceTagger(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	return &ReconcilePersistentVolume{
+		client:         fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		scheme:         scheme.Scheme,
+		recorder:       recorder,
+		resourceTagger: mockResourceTagger,
+	}, mockResourceTagger, recorder
+}
+
+var req = reconcile.Request{
+	NamespacedName: types.NamespacedName{
+		Name: volumeName,
+	},
+}
+
+func TestReconcilePersistentVolume_Reconcile(t *testing.T) {
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			flags.VolumeTags = map[string]string{}
+			flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+			if testData.volumeTags != nil {
+				flags.VolumeTags = testData.volumeTags
+			}
+
+			expectedTags := claimTags
+			if testData.expectedTags != nil {
+				expectedTags = testData.expectedTags
+			}
+
+			volumeClaim := claim
+			if testData.claim != nil {
+				volumeClaim = testData.claim
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r, mockResourceTagger, recorder := newReconcilePersistentVolume(t, ctrl,
+				testData.resource, volumeClaim, namespace)
+
+			numberOfTimesToTagVolume := 0
+			if testData.shouldTagVolume {
+				numberOfTimesToTagVolume = 1
+			}
+
+			mockResourceTagger.
+				EXPECT().EnsureResourceHasTags(testData.expectedVolumeID, expectedTags, gomock.Any()).
+				Return(testData.expectedError).
+				Times(numberOfTimesToTagVolume)
+
+			_, err := r.Reconcile(req)
+
+			if testData.expectedEvent != "" {
+				assert.Equal(t, testData.expectedEvent, <-recorder.Events)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+
+			if testData.expectedErrorMessage != "" {
+				assert.EqualError(t, err, testData.expectedErrorMessage)
+				return
+			}
+
+			assert.Equal(t, testData.expectedError, err)
+		})
+	}
+}
+
+func TestReconcilePersistentVolume_ReconcileTagsOnce(t *testing.T) {
+	flags.VolumeTags = map[string]string{
+		"app": `{{ .Labels "app" }}`,
+	}
+	flags.ConflictPolicy = string(tagging.ConflictPolicyKeepExisting)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, mockResourceTagger, _ := newReconcilePersistentVolume(t, ctrl, csiVolume, claim.DeepCopy(), namespace)
+
+	mockResourceTagger.
+		EXPECT().EnsureResourceHasTags(volumeID, map[string]string{"app": "database", "cost-center": "1234"},
+		tagging.ConflictPolicies{
+			"app":         tagging.ConflictPolicyKeepExisting,
+			"cost-center": tagging.ConflictPolicyKeepExisting,
+		}).
+		Return(nil).
+		Times(1)
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+
+	// The volume is not tagged again while the claim stays the same
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+
+	// A change to the labels of the claim tags the volume again
+	updatedClaim := &corev1.PersistentVolumeClaim{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespaceName, Name: claimName}, updatedClaim)
+	assert.NoError(t, err)
+
+	updatedClaim.Labels["app"] = "cache"
+	err = r.client.Update(context.TODO(), updatedClaim)
+	assert.NoError(t, err)
+
+	mockResourceTagger.
+		EXPECT().EnsureResourceHasTags(volumeID, map[string]string{"app": "cache", "cost-center": "1234"}, gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+}
+
+func TestReconcilePersistentVolume_ReconcileForgetsDeletedVolumes(t *testing.T) {
+	flags.VolumeTags = map[string]string{}
+	flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, mockResourceTagger, _ := newReconcilePersistentVolume(t, ctrl, csiVolume.DeepCopy(), claim, namespace)
+
+	mockResourceTagger.EXPECT().EnsureResourceHasTags(volumeID, claimTags, gomock.Any()).Return(nil).Times(2)
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+
+	err = r.client.Delete(context.TODO(), csiVolume.DeepCopy())
+	assert.NoError(t, err)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+
+	// A volume created again with the same name is tagged again
+	err = r.client.Create(context.TODO(), csiVolume.DeepCopy())
+	assert.NoError(t, err)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+}
+
+func TestReconcilePersistentVolume_ReconcileRetriesFailedTagging(t *testing.T) {
+	flags.VolumeTags = map[string]string{}
+	flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, mockResourceTagger, _ := newReconcilePersistentVolume(t, ctrl, csiVolume, claim, namespace)
+
+	gomock.InOrder(
+		mockResourceTagger.EXPECT().EnsureResourceHasTags(volumeID, claimTags, gomock.Any()).Return(errors.New("error")),
+		mockResourceTagger.EXPECT().EnsureResourceHasTags(volumeID, claimTags, gomock.Any()).Return(nil),
+	)
+
+	_, err := r.Reconcile(req)
+	assert.EqualError(t, err, "error")
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+}
+
+func TestMapClaimToVolume(t *testing.T) {
+	assert.Equal(t, []reconcile.Request{req}, mapClaimToVolume(handler.MapObject{Object: claim}))
+	assert.Empty(t, mapClaimToVolume(handler.MapObject{Object: &corev1.PersistentVolumeClaim{}}))
+}
+
+func TestClaimMetadataChanged(t *testing.T) {
+	relabeledClaim := claim.DeepCopy()
+	relabeledClaim.Labels["app"] = "cache"
+
+	annotatedClaim := claim.DeepCopy()
+	annotatedClaim.Annotations = map[string]string{constants.TagsAnnotation: `{"team": "b"}`}
+
+	boundClaim := claim.DeepCopy()
+	boundClaim.Status.Phase = corev1.ClaimBound
+
+	tests := []struct {
+		testName string
+		newClaim *corev1.PersistentVolumeClaim
+		expected bool
+	}{
+		{testName: "labels changed", newClaim: relabeledClaim, expected: true},
+		{testName: "annotations changed", newClaim: annotatedClaim, expected: true},
+		{testName: "status changed", newClaim: boundClaim, expected: false},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, claimMetadataChanged(event.UpdateEvent{
+				ObjectOld: claim,
+				ObjectNew: testData.newClaim,
+			}))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	pkgerrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_service")
+
+// Add creates a new Service Controller and adds it to the Manager when tagging load balancers is enabled. The
+// Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !flags.TagLoadBalancers {
+		return nil
+	}
+
+	reconciler, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, reconciler)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	awsSession, err := aws.GetAwsSessionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileService{
+		client:             mgr.GetClient(),
+		scheme:             mgr.GetScheme(),
+		recorder:           mgr.GetEventRecorderFor("node-tagger"),
+		loadBalancerTagger: aws.NewLoadBalancerTagger(elb.New(awsSession), elbv2.New(awsSession)),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("service-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: flags.MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource Service
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileService implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileService{}
+
+// ReconcileService reconciles a Service object
+type ReconcileService struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client             client.Client
+	scheme             *runtime.Scheme
+	recorder           record.EventRecorder
+	loadBalancerTagger aws.LoadBalancerTagger
+	// taggedServices holds the load balancer hostnames and tags last applied for each Service, so the load
+	// balancers are only tagged again when the Service changes
+	taggedServices sync.Map
+}
+
+// Reconcile reads that state of the cluster for a Service object and adds tags to its load balancers if necessary
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileService) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Service.Namespace", request.Namespace, "Service.Name", request.Name)
+	reqLogger.V(constants.DebugLogVerbosity).Info("Reconciling Service")
+
+	// Fetch the Service instance
+	instance := &corev1.Service{}
+
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			r.taggedServices.Delete(request.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// Service has no load balancer so skip it. Return and don't requeue
+	if instance.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Service is not of type LoadBalancer. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	// Load balancer is not provisioned yet. Return and don't requeue, the status update will trigger a new reconcile
+	hostnames := loadBalancerHostnames(instance)
+	if len(hostnames) == 0 {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Service has no load balancer hostname yet. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	namespace := &corev1.Namespace{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Namespace}, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	tags, err := tagging.ServiceTags(flags.LoadBalancerTags, instance, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	fingerprint := strings.Join(hostnames, ",") + "\n" + tagging.Fingerprint(tags)
+	if applied, found := r.taggedServices.Load(request.NamespacedName); found && applied == fingerprint {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Load balancers already tagged.")
+		return reconcile.Result{}, nil
+	}
+
+	conflictPolicies := tagging.ConflictPolicies{}
+	for key := range tags {
+		conflictPolicies[key] = tagging.ConflictPolicy(flags.ConflictPolicy)
+	}
+
+	for _, hostname := range hostnames {
+		err = r.loadBalancerTagger.EnsureLoadBalancerHasTags(hostname, tags, conflictPolicies)
+		if err != nil {
+			var conflictErr *tagging.ConflictError
+			if pkgerrors.As(err, &conflictErr) {
+				r.recorder.Event(instance, corev1.EventTypeWarning, "TagConflict", conflictErr.Error())
+			}
+
+			return reconcile.Result{}, err
+		}
+	}
+
+	r.taggedServices.Store(request.NamespacedName, fingerprint)
+
+	return reconcile.Result{}, nil
+}
+
+// loadBalancerHostnames returns the sorted hostnames of the load balancers of the service. AWS load balancers are
+// only ever reported by hostname, so ingress points with an IP only are ignored.
+func loadBalancerHostnames(service *corev1.Service) []string {
+	hostnames := []string{}
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			hostnames = append(hostnames, ingress.Hostname)
+		}
+	}
+
+	sort.Strings(hostnames)
+
+	return hostnames
+}
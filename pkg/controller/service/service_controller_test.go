@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	serviceName   = "ingress"
+	namespaceName = "team-a"
+	hostname      = "a1b2c3-0123456789.elb.eu-west-1.amazonaws.com"
+)
+
+type testReconcileItem struct {
+	testName             string
+	resource             *corev1.Service
+	loadBalancerTags     map[string]string
+	expectedTags         map[string]string
+	expectedEvent        string
+	expectedError        error
+	expectedErrorMessage string
+	shouldTagHostnames   []string
+}
+
+var namespace = &corev1.Namespace{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: namespaceName,
+		Annotations: map[string]string{
+			constants.TagsAnnotation: `{"cost-center": "1234"}`,
+		},
+	},
+}
+
+func newService(serviceType corev1.ServiceType, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: namespaceName,
+			Labels: map[string]string{
+				"app": "nginx",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: serviceType,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: ingress,
+			},
+		},
+	}
+}
+
+var loadBalancerService = newService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{Hostname: hostname})
+
+var serviceTags = map[string]string{
+	"cost-center": "1234",
+}
+
+var tests = []testReconcileItem{
+	{
+		testName: "service not of type load balancer",
+		resource: newService(corev1.ServiceTypeClusterIP),
+	},
+	{
+		testName: "service without load balancer hostname",
+		resource: newService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+	},
+	{
+		testName:           "service with load balancer",
+		resource:           loadBalancerService,
+		shouldTagHostnames: []string{hostname},
+	},
+	{
+		testName: "service with several load balancers",
+		resource: newService(corev1.ServiceTypeLoadBalancer,
+			corev1.LoadBalancerIngress{Hostname: "second.elb.eu-west-1.amazonaws.com"},
+			corev1.LoadBalancerIngress{Hostname: hostname},
+		),
+		shouldTagHostnames: []string{hostname, "second.elb.eu-west-1.amazonaws.com"},
+	},
+	{
+		testName: "service tags from labels and annotation",
+		resource: func() *corev1.Service {
+			service := loadBalancerService.DeepCopy()
+			service.Annotations = map[string]string{
+				constants.TagsAnnotation: `{"cost-center": "5678", "owner": "{{ .Namespace }}"}`,
+			}
+			return service
+		}(),
+		loadBalancerTags: map[string]string{
+			"app": `{{ .Labels "app" }}`,
+		},
+		expectedTags: map[string]string{
+			"app":         "nginx",
+			"cost-center": "5678",
+			"owner":       "team-a",
+		},
+		shouldTagHostnames: []string{hostname},
+	},
+	{
+		testName: "service invalid annotation",
+		resource: func() *corev1.Service {
+			service := loadBalancerService.DeepCopy()
+			service.Annotations = map[string]string{
+				constants.TagsAnnotation: "owner=team-a",
+			}
+			return service
+		}(),
+		expectedErrorMessage: "invalid node-tagger.ouzi.dev/tags annotation for service team-a/ingress: tags " +
+			"annotation must be a JSON object of string keys to string values: invalid character 'o' looking for " +
+			"beginning of value",
+	},
+	{
+		testName:           "service error tagging",
+		resource:           loadBalancerService,
+		expectedError:      errors.New("error"),
+		shouldTagHostnames: []string{hostname},
+	},
+	{
+		testName: "service conflicting tags",
+		resource: loadBalancerService,
+		expectedError: &tagging.ConflictError{
+			Conflicts: []tagging.Conflict{
+				{Key: "cost-center", ExistingValue: "0000", DesiredValue: "1234"},
+			},
+		},
+		expectedEvent: `Warning TagConflict conflicting tags: cost-center (existing value "0000", ` +
+			`desired value "1234")`,
+		shouldTagHostnames: []string{hostname},
+	},
+}
+
+func newReconcileService(t *testing.T, ctrl *gomock.Controller,
+	objs ...runtime.Object) (*ReconcileService, *mocks.MockLoadBalancerTagger, *record.FakeRecorder) {
+	t.Helper()
+
+	mockLoadBalancerTagger := mocks.NewMockLoadBalancerTagger(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	return &ReconcileService{
+		client:             fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		scheme:             scheme.Scheme,
+		recorder:           recorder,
+		loadBalancerTagger: mockLoadBalancerTagger,
+	}, mockLoadBalancerTagger, recorder
+}
+
+var req = reconcile.Request{
+	NamespacedName: types.NamespacedName{
+		Namespace: namespaceName,
+		Name:      serviceName,
+	},
+}
+
+func TestReconcileService_Reconcile(t *testing.T) {
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			flags.LoadBalancerTags = map[string]string{}
+			flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+			if testData.loadBalancerTags != nil {
+				flags.LoadBalancerTags = testData.loadBalancerTags
+			}
+
+			expectedTags := serviceTags
+			if testData.expectedTags != nil {
+				expectedTags = testData.expectedTags
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r, mockLoadBalancerTagger, recorder := newReconcileService(t, ctrl, testData.resource, namespace)
+
+			for _, expectedHostname := range testData.shouldTagHostnames {
+				mockLoadBalancerTagger.
+					EXPECT().EnsureLoadBalancerHasTags(expectedHostname, expectedTags, gomock.Any()).
+					Return(testData.expectedError).
+					Times(1)
+			}
+
+			_, err := r.Reconcile(req)
+
+			if testData.expectedEvent != "" {
+				assert.Equal(t, testData.expectedEvent, <-recorder.Events)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+
+			if testData.expectedErrorMessage != "" {
+				assert.EqualError(t, err, testData.expectedErrorMessage)
+				return
+			}
+
+			assert.Equal(t, testData.expectedError, err)
+		})
+	}
+}
+
+func TestReconcileService_ReconcileTagsAgainWhenServiceChanges(t *testing.T) {
+	flags.LoadBalancerTags = map[string]string{
+		"app": `{{ .Labels "app" }}`,
+	}
+	flags.ConflictPolicy = string(tagging.ConflictPolicyFail)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, mockLoadBalancerTagger, _ := newReconcileService(t, ctrl, loadBalancerService.DeepCopy(), namespace)
+
+	mockLoadBalancerTagger.
+		EXPECT().EnsureLoadBalancerHasTags(hostname, map[string]string{"app": "nginx", "cost-center": "1234"},
+		tagging.ConflictPolicies{
+			"app":         tagging.ConflictPolicyFail,
+			"cost-center": tagging.ConflictPolicyFail,
+		}).
+		Return(nil).
+		Times(1)
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+
+	// The load balancer is not tagged again while the service stays the same
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+
+	updatedService := &corev1.Service{}
+	err = r.client.Get(context.TODO(), req.NamespacedName, updatedService)
+	assert.NoError(t, err)
+
+	updatedService.Labels["app"] = "envoy"
+	err = r.client.Update(context.TODO(), updatedService)
+	assert.NoError(t, err)
+
+	mockLoadBalancerTagger.
+		EXPECT().EnsureLoadBalancerHasTags(hostname, map[string]string{"app": "envoy", "cost-center": "1234"},
+		gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,252 @@
+package volumesnapshotcontent
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/ouzi-dev/node-tagger/pkg/aws"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	pkgerrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_volumesnapshotcontent")
+
+// The snapshot types are served by the CRDs of the external snapshotter, so they are handled as unstructured
+// objects instead of depending on its client
+var (
+	volumeSnapshotContentGVK = schema.GroupVersionKind{
+		Group:   "snapshot.storage.k8s.io",
+		Version: "v1beta1",
+		Kind:    "VolumeSnapshotContent",
+	}
+	volumeSnapshotGVK = schema.GroupVersionKind{
+		Group:   "snapshot.storage.k8s.io",
+		Version: "v1beta1",
+		Kind:    "VolumeSnapshot",
+	}
+)
+
+func newVolumeSnapshotContent() *unstructured.Unstructured {
+	content := &unstructured.Unstructured{}
+	content.SetGroupVersionKind(volumeSnapshotContentGVK)
+
+	return content
+}
+
+func newVolumeSnapshot() *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+
+	return snapshot
+}
+
+// Add creates a new VolumeSnapshotContent Controller and adds it to the Manager when tagging snapshots is enabled.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if !flags.TagSnapshots {
+		return nil
+	}
+
+	reconciler, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, reconciler)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	awsSession, err := aws.GetAwsSessionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileVolumeSnapshotContent{
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		recorder:       mgr.GetEventRecorderFor("node-tagger"),
+		resourceTagger: aws.NewEC2ResourceTagger(ec2.New(awsSession), constants.ManagedKeysTag),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("volumesnapshotcontent-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: flags.MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource VolumeSnapshotContent
+	err = c.Watch(&source.Kind{Type: newVolumeSnapshotContent()}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileVolumeSnapshotContent implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileVolumeSnapshotContent{}
+
+// ReconcileVolumeSnapshotContent reconciles a VolumeSnapshotContent object
+type ReconcileVolumeSnapshotContent struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client         client.Client
+	scheme         *runtime.Scheme
+	recorder       record.EventRecorder
+	resourceTagger aws.ResourceTagger
+	// taggedSnapshots holds the snapshot ID and tags last applied for each VolumeSnapshotContent, so every snapshot
+	// is only tagged once
+	taggedSnapshots sync.Map
+}
+
+// Reconcile reads that state of the cluster for a VolumeSnapshotContent object and adds tags derived from the claim
+// the snapshot was taken from to the underlying EBS snapshot if necessary
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileVolumeSnapshotContent) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("VolumeSnapshotContent.Name", request.Name)
+	reqLogger.V(constants.DebugLogVerbosity).Info("Reconciling VolumeSnapshotContent")
+
+	// Fetch the VolumeSnapshotContent instance
+	instance := newVolumeSnapshotContent()
+
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			r.taggedSnapshots.Delete(request.Name)
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	// Snapshot is not taken by the EBS CSI driver so skip it. Return and don't requeue
+	driver, _, _ := unstructured.NestedString(instance.Object, "spec", "driver")
+	if driver != constants.EBSCSIDriver {
+		reqLogger.V(constants.DebugLogVerbosity).Info("VolumeSnapshotContent is not an EBS snapshot. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	// Snapshot is not created yet. Return and don't requeue, the status update will trigger a new reconcile
+	snapshotID, _, _ := unstructured.NestedString(instance.Object, "status", "snapshotHandle")
+	if snapshotID == "" {
+		reqLogger.V(constants.DebugLogVerbosity).Info("VolumeSnapshotContent has no snapshot handle yet. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	claim, err := r.sourceClaim(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The claim the snapshot was taken from is unknown or gone, so there are no tags to derive
+	if claim == nil {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Source PersistentVolumeClaim not found. Skipping")
+		return reconcile.Result{}, nil
+	}
+
+	namespace := &corev1.Namespace{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: claim.Namespace}, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	tags, err := tagging.ClaimTags(flags.VolumeTags, claim, namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	fingerprint := snapshotID + "\n" + tagging.Fingerprint(tags)
+	if applied, found := r.taggedSnapshots.Load(instance.GetName()); found && applied == fingerprint {
+		reqLogger.V(constants.DebugLogVerbosity).Info("Snapshot already tagged.", "Snapshot.ID", snapshotID)
+		return reconcile.Result{}, nil
+	}
+
+	conflictPolicies := tagging.ConflictPolicies{}
+	for key := range tags {
+		conflictPolicies[key] = tagging.ConflictPolicy(flags.ConflictPolicy)
+	}
+
+	err = r.resourceTagger.EnsureResourceHasTags(snapshotID, tags, conflictPolicies)
+	if err != nil {
+		var conflictErr *tagging.ConflictError
+		if pkgerrors.As(err, &conflictErr) {
+			r.recorder.Event(instance, corev1.EventTypeWarning, "TagConflict", conflictErr.Error())
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	r.taggedSnapshots.Store(instance.GetName(), fingerprint)
+
+	return reconcile.Result{}, nil
+}
+
+// sourceClaim follows the VolumeSnapshot bound to the content to the PersistentVolumeClaim it was taken from. It
+// returns nil when any of them cannot be found, such as for pre-provisioned snapshots or deleted claims.
+func (r *ReconcileVolumeSnapshotContent) sourceClaim(
+	content *unstructured.Unstructured) (*corev1.PersistentVolumeClaim, error) {
+	snapshotNamespace, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "namespace")
+	snapshotName, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "name")
+
+	if snapshotNamespace == "" || snapshotName == "" {
+		return nil, nil
+	}
+
+	snapshot := newVolumeSnapshot()
+
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: snapshotNamespace, Name: snapshotName}, snapshot)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	claimName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	if claimName == "" {
+		return nil, nil
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: snapshotNamespace, Name: claimName}, claim)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return claim, nil
+}
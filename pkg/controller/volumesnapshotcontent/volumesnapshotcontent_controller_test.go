@@ -0,0 +1,280 @@
+package volumesnapshotcontent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/flags"
+	"github.com/ouzi-dev/node-tagger/pkg/mocks"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	contentName   = "snapcontent-1"
+	snapshotName  = "backup"
+	claimName     = "data"
+	namespaceName = "team-a"
+	snapshotID    = "snap-0123456789abcdef0"
+)
+
+type testReconcileItem struct {
+	testName             string
+	resource             *unstructured.Unstructured
+	snapshot             *unstructured.Unstructured
+	volumeTags           map[string]string
+	expectedTags         map[string]string
+	expectedEvent        string
+	expectedError        error
+	expectedErrorMessage string
+	shouldTagSnapshot    bool
+}
+
+var namespace = &corev1.Namespace{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: namespaceName,
+		Labels: map[string]string{
+			"team": "a",
+		},
+		Annotations: map[string]string{
+			constants.TagsAnnotation: `{"cost-center": "1234"}`,
+		},
+	},
+}
+
+var claim = &corev1.PersistentVolumeClaim{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      claimName,
+		Namespace: namespaceName,
+		Labels: map[string]string{
+			"app": "database",
+		},
+	},
+}
+
+func newContent(driver string, snapshotHandle string) *unstructured.Unstructured {
+	content := newVolumeSnapshotContent()
+	content.SetName(contentName)
+	content.Object["spec"] = map[string]interface{}{
+		"driver": driver,
+		"volumeSnapshotRef": map[string]interface{}{
+			"namespace": namespaceName,
+			"name":      snapshotName,
+		},
+	}
+
+	if snapshotHandle != "" {
+		content.Object["status"] = map[string]interface{}{
+			"snapshotHandle": snapshotHandle,
+		}
+	}
+
+	return content
+}
+
+func newSnapshot(source map[string]interface{}) *unstructured.Unstructured {
+	snapshot := newVolumeSnapshot()
+	snapshot.SetNamespace(namespaceName)
+	snapshot.SetName(snapshotName)
+	snapshot.Object["spec"] = map[string]interface{}{
+		"source": source,
+	}
+
+	return snapshot
+}
+
+var ebsContent = newContent(constants.EBSCSIDriver, snapshotID)
+
+var claimSnapshot = newSnapshot(map[string]interface{}{
+	"persistentVolumeClaimName": claimName,
+})
+
+var claimTags = map[string]string{
+	"cost-center": "1234",
+}
+
+var tests = []testReconcileItem{
+	{
+		testName:          "snapshot not taken by the ebs csi driver",
+		resource:          newContent("pd.csi.storage.gke.io", "snapshot"),
+		shouldTagSnapshot: false,
+	},
+	{
+		testName:          "snapshot without a handle",
+		resource:          newContent(constants.EBSCSIDriver, ""),
+		shouldTagSnapshot: false,
+	},
+	{
+		testName: "pre-provisioned snapshot",
+		resource: ebsContent,
+		snapshot: newSnapshot(map[string]interface{}{
+			"volumeSnapshotContentName": contentName,
+		}),
+		shouldTagSnapshot: false,
+	},
+	{
+		testName: "snapshot of a deleted claim",
+		resource: ebsContent,
+		snapshot: newSnapshot(map[string]interface{}{
+			"persistentVolumeClaimName": "deleted",
+		}),
+		shouldTagSnapshot: false,
+	},
+	{
+		testName:          "snapshot tagged from the claim",
+		resource:          ebsContent,
+		shouldTagSnapshot: true,
+	},
+	{
+		testName: "snapshot tags rendered against the claim",
+		resource: ebsContent,
+		volumeTags: map[string]string{
+			"app":  `{{ .Labels "app" }}`,
+			"team": `{{ .NamespaceLabels "team" }}`,
+		},
+		expectedTags: map[string]string{
+			"app":         "database",
+			"team":        "a",
+			"cost-center": "1234",
+		},
+		shouldTagSnapshot: true,
+	},
+	{
+		testName: "snapshot invalid tags",
+		resource: ebsContent,
+		volumeTags: map[string]string{
+			"aws:app": "database",
+		},
+		expectedErrorMessage: `claim team-a/data: invalid tags: key "aws:app" uses the reserved aws: prefix`,
+		shouldTagSnapshot:    false,
+	},
+	{
+		testName:          "snapshot error tagging",
+		resource:          ebsContent,
+		expectedError:     errors.New("error"),
+		shouldTagSnapshot: true,
+	},
+	{
+		testName: "snapshot conflicting tags",
+		resource: ebsContent,
+		expectedError: &tagging.ConflictError{
+			Conflicts: []tagging.Conflict{
+				{Key: "cost-center", ExistingValue: "0000", DesiredValue: "1234"},
+			},
+		},
+		expectedEvent: `Warning TagConflict conflicting tags: cost-center (existing value "0000", ` +
+			`desired value "1234")`,
+		shouldTagSnapshot: true,
+	},
+}
+
+func newReconcileVolumeSnapshotContent(t *testing.T, ctrl *gomock.Controller,
+	objs ...runtime.Object) (*ReconcileVolumeSnapshotContent, *mocks.MockResourceTagger, *record.FakeRecorder) {
+	t.Helper()
+
+	mockResourceTagger := mocks.NewMockResourceTagger(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	return &ReconcileVolumeSnapshotContent{
+		client:         fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		scheme:         scheme.Scheme,
+		recorder:       recorder,
+		resourceTagger: mockResourceTagger,
+	}, mockResourceTagger, recorder
+}
+
+var req = reconcile.Request{
+	NamespacedName: types.NamespacedName{
+		Name: contentName,
+	},
+}
+
+func TestReconcileVolumeSnapshotContent_Reconcile(t *testing.T) {
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			flags.VolumeTags = map[string]string{}
+			flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+			if testData.volumeTags != nil {
+				flags.VolumeTags = testData.volumeTags
+			}
+
+			expectedTags := claimTags
+			if testData.expectedTags != nil {
+				expectedTags = testData.expectedTags
+			}
+
+			snapshot := claimSnapshot
+			if testData.snapshot != nil {
+				snapshot = testData.snapshot
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r, mockResourceTagger, recorder := newReconcileVolumeSnapshotContent(t, ctrl,
+				testData.resource.DeepCopy(), snapshot.DeepCopy(), claim, namespace)
+
+			numberOfTimesToTagSnapshot := 0
+			if testData.shouldTagSnapshot {
+				numberOfTimesToTagSnapshot = 1
+			}
+
+			mockResourceTagger.
+				EXPECT().EnsureResourceHasTags(snapshotID, expectedTags, gomock.Any()).
+				Return(testData.expectedError).
+				Times(numberOfTimesToTagSnapshot)
+
+			_, err := r.Reconcile(req)
+
+			if testData.expectedEvent != "" {
+				assert.Equal(t, testData.expectedEvent, <-recorder.Events)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+
+			if testData.expectedErrorMessage != "" {
+				assert.EqualError(t, err, testData.expectedErrorMessage)
+				return
+			}
+
+			assert.Equal(t, testData.expectedError, err)
+		})
+	}
+}
+
+func TestReconcileVolumeSnapshotContent_ReconcileTagsOnce(t *testing.T) {
+	flags.VolumeTags = map[string]string{}
+	flags.ConflictPolicy = string(tagging.ConflictPolicyOverwrite)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, mockResourceTagger, _ := newReconcileVolumeSnapshotContent(t, ctrl,
+		ebsContent.DeepCopy(), claimSnapshot.DeepCopy(), claim, namespace)
+
+	mockResourceTagger.
+		EXPECT().EnsureResourceHasTags(snapshotID, claimTags, tagging.ConflictPolicies{
+		"cost-center": tagging.ConflictPolicyOverwrite,
+	}).
+		Return(nil).
+		Times(1)
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+}
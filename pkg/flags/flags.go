@@ -1,4 +1,26 @@
 package flags
 
+import "time"
+
 var InstanceTags map[string]string
+var Providers []string
+var ConflictPolicy string
+var TagRootVolumes bool
+var TagDataVolumes bool
+var TagNetworkInterfaces bool
+var TagPersistentVolumes bool
+var VolumeTags map[string]string
+var TagSnapshots bool
+var TagLoadBalancers bool
+var LoadBalancerTags map[string]string
 var LeaderElectionNamespace string
+var AssumeRoleConfig string
+var AWSBatchWindow time.Duration
+var AWSTagCacheTTL time.Duration
+var ResyncPeriod time.Duration
+var MaxConcurrentReconciles int
+var AWSDescribeRate float64
+var AWSDescribeBurst int
+var AWSMutateRate float64
+var AWSMutateBurst int
+var AWSMaxRetries int
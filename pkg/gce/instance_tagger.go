@@ -0,0 +1,233 @@
+package gce
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ComputeEndpoint is the base URL of the Compute Engine API
+	ComputeEndpoint = "https://compute.googleapis.com/compute/v1/"
+	// ComputeScope is the OAuth2 scope needed to read and label instances
+	ComputeScope = "https://www.googleapis.com/auth/compute"
+
+	// maxSetLabelsAttempts bounds the retries when the labels of an instance change between reading and writing them
+	maxSetLabelsAttempts = 5
+)
+
+var log = logf.Log.WithName("gce_instance_tagger")
+
+// errLabelFingerprintMismatch is returned by setLabels when the labels changed since the fingerprint was read
+var errLabelFingerprintMismatch = errors.New("label fingerprint mismatch")
+
+// instanceRef identifies a Compute Engine instance
+type instanceRef struct {
+	project string
+	zone    string
+	name    string
+}
+
+func (i instanceRef) String() string {
+	return i.project + "/" + i.zone + "/" + i.name
+}
+
+// instance is the part of a Compute Engine instance resource needed to label it
+type instance struct {
+	Labels           map[string]string `json:"labels"`
+	LabelFingerprint string            `json:"labelFingerprint"`
+}
+
+type setLabelsRequest struct {
+	Labels           map[string]string `json:"labels"`
+	LabelFingerprint string            `json:"labelFingerprint"`
+}
+
+type instanceTagger struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewInstanceTagger returns a NodeTagger applying the tags as labels of the Compute Engine instances of the nodes.
+// The http client must add the credentials to the requests, e.g. one from golang.org/x/oauth2/google.
+func NewInstanceTagger(httpClient *http.Client, endpoint string) provider.NodeTagger {
+	return &instanceTagger{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/") + "/",
+	}
+}
+
+func (g *instanceTagger) EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+	conflictPolicies tagging.ConflictPolicies) (provider.TagResult, error) {
+	ref, err := parseProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return provider.TagResult{}, err
+	}
+
+	nodeLogger := log.WithValues("Node.Name", node.Name, "Instance", ref.String())
+
+	labels, conversions, err := ConvertTagsToLabels(tags)
+	if err != nil {
+		return provider.TagResult{}, errors.Wrapf(err, "node %s", node.Name)
+	}
+
+	for _, conversion := range conversions {
+		nodeLogger.Info("Tag changed to fit the GCE label rules.", "Tag.Key", conversion.TagKey,
+			"Tag.Value", conversion.TagValue, "Label.Key", conversion.LabelKey, "Label.Value", conversion.LabelValue)
+	}
+
+	labelPolicies := tagging.ConflictPolicies{}
+	for key := range tags {
+		labelPolicies[convertLabelKey(key)] = conflictPolicies.For(key)
+	}
+
+	for attempt := 1; attempt <= maxSetLabelsAttempts; attempt++ {
+		current, err := g.getInstance(ref)
+		if err != nil {
+			return provider.TagResult{}, err
+		}
+
+		otherLabels, managedLabels := splitManagedLabels(current.Labels)
+
+		merged, err := tagging.Merge(otherLabels, parseManagedLabels(otherLabels, managedLabels), labels,
+			labelPolicies)
+		if err != nil {
+			return provider.TagResult{}, err
+		}
+
+		desiredManagedLabels := formatManagedLabels(merged.ManagedKeys)
+		if !merged.Changed() && reflect.DeepEqual(managedLabels, desiredManagedLabels) {
+			nodeLogger.V(constants.DebugLogVerbosity).Info("Instance already labelled.")
+			return provider.TagResult{}, nil
+		}
+
+		desired := merged.Tags
+		for key, value := range desiredManagedLabels {
+			desired[key] = value
+		}
+
+		nodeLogger.Info("Labelling instance.")
+
+		err = g.setLabels(ref, desired, current.LabelFingerprint)
+		if err == errLabelFingerprintMismatch {
+			nodeLogger.V(constants.DebugLogVerbosity).Info("Instance labels changed concurrently. Retrying",
+				"Attempt", attempt)
+			continue
+		}
+
+		if err != nil {
+			return provider.TagResult{}, err
+		}
+
+		// The keys are the ones of the labels, which may differ from the ones of the tags
+		return provider.NewTagResult(merged.SetKeys, merged.RemovedKeys), nil
+	}
+
+	return provider.TagResult{}, errors.Errorf("the labels of instance %s kept changing concurrently, gave up after "+
+		"%d attempts", ref, maxSetLabelsAttempts)
+}
+
+func (g *instanceTagger) getInstance(ref instanceRef) (*instance, error) {
+	result := &instance{}
+
+	err := g.do(http.MethodGet, g.instanceURL(ref), nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (g *instanceTagger) setLabels(ref instanceRef, labels map[string]string, fingerprint string) error {
+	body, err := json.Marshal(&setLabelsRequest{Labels: labels, LabelFingerprint: fingerprint})
+	if err != nil {
+		return err
+	}
+
+	return g.do(http.MethodPost, g.instanceURL(ref)+"/setLabels", body, nil)
+}
+
+func (g *instanceTagger) instanceURL(ref instanceRef) string {
+	return fmt.Sprintf("%sprojects/%s/zones/%s/instances/%s", g.endpoint,
+		url.PathEscape(ref.project), url.PathEscape(ref.zone), url.PathEscape(ref.name))
+}
+
+func (g *instanceTagger) do(method string, requestURL string, body []byte, result interface{}) error {
+	request, err := http.NewRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := g.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	// The Compute API rejects a stale label fingerprint with a failed precondition
+	if response.StatusCode == http.StatusPreconditionFailed {
+		return errLabelFingerprintMismatch
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = errors.Errorf("%s %s failed with status %s: %s", method, requestURL, response.Status,
+			strings.TrimSpace(string(responseBody)))
+
+		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+			return &provider.PermissionDeniedError{Err: err}
+		}
+
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(responseBody, result)
+}
+
+// parseProviderID parses a ProviderID of the form gce://<project>/<zone>/<instance>
+func parseProviderID(providerID string) (instanceRef, error) {
+	parts := strings.Split(strings.TrimPrefix(providerID, "gce://"), "/")
+	if !strings.HasPrefix(providerID, "gce://") || len(parts) != 3 || parts[0] == "" || parts[1] == "" ||
+		parts[2] == "" {
+		return instanceRef{}, errors.Errorf("invalid GCE ProviderID %q, must be gce://<project>/<zone>/<instance>",
+			providerID)
+	}
+
+	return instanceRef{project: parts[0], zone: parts[1], name: parts[2]}, nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
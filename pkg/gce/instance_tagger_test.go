@@ -0,0 +1,224 @@
+package gce
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ouzi-dev/node-tagger/pkg/provider"
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const instancePath = "/projects/project/zones/europe-west1-b/instances/instance"
+
+var inputNode = &corev1.Node{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "gke-node",
+	},
+	Spec: corev1.NodeSpec{
+		ProviderID: "gce://project/europe-west1-b/instance",
+	},
+}
+
+var inputTags = map[string]string{
+	"team":        "platform",
+	"Cost-Center": "1234",
+}
+
+// fakeCompute is an in-process stand-in of the instance endpoints of the Compute API, including the label fingerprint
+// checks
+type fakeCompute struct {
+	mu     sync.Mutex
+	labels map[string]string
+	// fingerprint is bumped on every change to the labels
+	fingerprint int
+	// concurrentChanges is the number of setLabels calls that find the labels changed by someone else
+	concurrentChanges int
+	setLabelsCalls    int
+	getStatus         int
+}
+
+func newFakeCompute(t *testing.T, labels map[string]string) (*fakeCompute, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeCompute{labels: labels}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeCompute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == instancePath:
+		if f.getStatus != 0 {
+			http.Error(w, `{"error": {"message": "forbidden"}}`, f.getStatus)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(&instance{Labels: f.labels, LabelFingerprint: strconv.Itoa(f.fingerprint)})
+	case r.Method == http.MethodPost && r.URL.Path == instancePath+"/setLabels":
+		f.setLabelsCalls++
+
+		request := &setLabelsRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if f.concurrentChanges > 0 {
+			f.concurrentChanges--
+			f.fingerprint++
+		}
+
+		if request.LabelFingerprint != strconv.Itoa(f.fingerprint) {
+			http.Error(w, `{"error": {"message": "Labels fingerprint either invalid or resource labels have changed"}}`,
+				http.StatusPreconditionFailed)
+			return
+		}
+
+		f.labels = request.Labels
+		f.fingerprint++
+		_, _ = w.Write([]byte(`{"kind": "compute#operation"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_ProviderIDIsInvalid(t *testing.T) {
+	subject := NewInstanceTagger(http.DefaultClient, ComputeEndpoint)
+
+	node := inputNode.DeepCopy()
+	node.Spec.ProviderID = "gce://project/instance"
+
+	_, err := subject.EnsureInstanceNodeHasTags(node, inputTags, nil)
+
+	assert.EqualError(t, err, `invalid GCE ProviderID "gce://project/instance", must be `+
+		`gce://<project>/<zone>/<instance>`)
+}
+
+func TestEnsureInstanceNodeHasTags_MergesLabels(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{"goog-gke-node": "", "team": "platform"})
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"cost-center"}, nil), result)
+	assert.Equal(t, map[string]string{
+		"goog-gke-node":         "",
+		"team":                  "platform",
+		"cost-center":           "1234",
+		"node-tagger-managed-0": hashManagedKey("cost-center"),
+	}, fake.labels)
+	assert.Equal(t, 1, fake.setLabelsCalls)
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsNoError_If_InstanceAlreadyLabelled(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{"team": "platform", "cost-center": "1234"})
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+	assert.Equal(t, 0, fake.setLabelsCalls)
+}
+
+func TestEnsureInstanceNodeHasTags_RetriesOnConcurrentLabelChanges(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{})
+	fake.concurrentChanges = 2
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"team":                  "platform",
+		"cost-center":           "1234",
+		"node-tagger-managed-0": hashManagedKey("team") + "_" + hashManagedKey("cost-center"),
+	}, fake.labels)
+	assert.Equal(t, 3, fake.setLabelsCalls)
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_LabelsKeepChanging(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{})
+	fake.concurrentChanges = maxSetLabelsAttempts
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.EqualError(t, err, "the labels of instance project/europe-west1-b/instance kept changing concurrently, "+
+		"gave up after 5 attempts")
+}
+
+func TestEnsureInstanceNodeHasTags_ReturnsError_If_GetInstanceFails(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{})
+	fake.getStatus = http.StatusForbidden
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+
+	assert.EqualError(t, err, "GET "+server.URL+instancePath+` failed with status 403 Forbidden: `+
+		`{"error": {"message": "forbidden"}}`)
+	assert.IsType(t, &provider.PermissionDeniedError{}, err)
+}
+
+func TestEnsureInstanceNodeHasTags_ResolvesConflicts(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{"team": "terraform", "cost-center": "0000"})
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, tagging.ConflictPolicies{
+		"team":        tagging.ConflictPolicyKeepExisting,
+		"Cost-Center": tagging.ConflictPolicyFail,
+	})
+
+	assert.Equal(t, &tagging.ConflictError{
+		Conflicts: []tagging.Conflict{
+			{Key: "cost-center", ExistingValue: "0000", DesiredValue: "1234"},
+		},
+	}, err)
+	assert.Equal(t, 0, fake.setLabelsCalls)
+
+	_, err = subject.EnsureInstanceNodeHasTags(inputNode, inputTags, tagging.ConflictPolicies{
+		"team": tagging.ConflictPolicyKeepExisting,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "terraform", "cost-center": "1234"}, fake.labels)
+}
+
+func TestEnsureInstanceNodeHasTags_RemovesManagedLabelsNoLongerDesired(t *testing.T) {
+	fake, server := newFakeCompute(t, map[string]string{"goog-gke-node": ""})
+	subject := NewInstanceTagger(server.Client(), server.URL)
+
+	_, err := subject.EnsureInstanceNodeHasTags(inputNode, inputTags, nil)
+	assert.NoError(t, err)
+
+	// A changed value of a managed label is not a conflict
+	result, err := subject.EnsureInstanceNodeHasTags(inputNode, map[string]string{"team": "data"},
+		tagging.ConflictPolicies{"team": tagging.ConflictPolicyFail})
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult([]string{"team"}, []string{"cost-center"}), result)
+	assert.Equal(t, map[string]string{
+		"goog-gke-node":         "",
+		"team":                  "data",
+		"node-tagger-managed-0": hashManagedKey("team"),
+	}, fake.labels)
+
+	result, err = subject.EnsureInstanceNodeHasTags(inputNode, map[string]string{}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.NewTagResult(nil, []string{"team"}), result)
+	assert.Equal(t, map[string]string{"goog-gke-node": ""}, fake.labels)
+}
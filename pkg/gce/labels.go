@@ -0,0 +1,95 @@
+package gce
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxLabelLength is the maximum length of the keys and values of GCE labels
+const MaxLabelLength = 63
+
+// labelKeyPrefix is prepended to the keys that do not start with a lowercase letter, as GCE label keys must
+const labelKeyPrefix = "tag-"
+
+// LabelConversion records a tag that could not be applied as a GCE label unchanged
+type LabelConversion struct {
+	TagKey     string
+	TagValue   string
+	LabelKey   string
+	LabelValue string
+}
+
+// ConvertTagsToLabels converts tags to GCE labels, which only allow lowercase letters, digits, underscores and
+// dashes, up to 63 characters, and keys starting with a lowercase letter. Uppercase letters are lowercased, any other
+// character is replaced with an underscore and the result is truncated. Every tag changed by the conversion is
+// returned so it can be reported, and tags whose keys convert to the same label key are rejected.
+func ConvertTagsToLabels(tags map[string]string) (map[string]string, []LabelConversion, error) {
+	labels := map[string]string{}
+	labelTagKeys := map[string]string{}
+	conversions := []LabelConversion{}
+
+	for _, key := range sortedKeys(tags) {
+		value := tags[key]
+		labelKey := convertLabelKey(key)
+		labelValue := convertLabelValue(value)
+
+		if isManagedLabel(labelKey) {
+			return nil, nil, errors.Errorf("tag %q converts to the GCE label key %q, reserved for node-tagger",
+				key, labelKey)
+		}
+
+		if otherKey, found := labelTagKeys[labelKey]; found {
+			return nil, nil, errors.Errorf("tags %q and %q both convert to the GCE label key %q",
+				otherKey, key, labelKey)
+		}
+
+		labels[labelKey] = labelValue
+		labelTagKeys[labelKey] = key
+
+		if labelKey != key || labelValue != value {
+			conversions = append(conversions, LabelConversion{
+				TagKey:     key,
+				TagValue:   value,
+				LabelKey:   labelKey,
+				LabelValue: labelValue,
+			})
+		}
+	}
+
+	return labels, conversions, nil
+}
+
+func convertLabelKey(key string) string {
+	labelKey := sanitizeLabel(key)
+	if labelKey == "" || labelKey[0] < 'a' || labelKey[0] > 'z' {
+		labelKey = labelKeyPrefix + labelKey
+	}
+
+	return truncateLabel(labelKey)
+}
+
+func convertLabelValue(value string) string {
+	return truncateLabel(sanitizeLabel(value))
+}
+
+func sanitizeLabel(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, value)
+}
+
+func truncateLabel(value string) string {
+	if len(value) > MaxLabelLength {
+		return value[:MaxLabelLength]
+	}
+
+	return value
+}
@@ -0,0 +1,51 @@
+package gce
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertTagsToLabels(t *testing.T) {
+	labels, conversions, err := ConvertTagsToLabels(map[string]string{
+		"team":                  "platform",
+		"Cost-Center":           "CC-1234",
+		"kubernetes.io/cluster": "owned",
+		"1st":                   "value",
+		"long":                  strings.Repeat("a", 70),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"team":                  "platform",
+		"cost-center":           "cc-1234",
+		"kubernetes_io_cluster": "owned",
+		"tag-1st":               "value",
+		"long":                  strings.Repeat("a", 63),
+	}, labels)
+	assert.Equal(t, []LabelConversion{
+		{TagKey: "1st", TagValue: "value", LabelKey: "tag-1st", LabelValue: "value"},
+		{TagKey: "Cost-Center", TagValue: "CC-1234", LabelKey: "cost-center", LabelValue: "cc-1234"},
+		{TagKey: "kubernetes.io/cluster", TagValue: "owned", LabelKey: "kubernetes_io_cluster", LabelValue: "owned"},
+		{TagKey: "long", TagValue: strings.Repeat("a", 70), LabelKey: "long", LabelValue: strings.Repeat("a", 63)},
+	}, conversions)
+}
+
+func TestConvertTagsToLabels_ReturnsError_If_KeysCollide(t *testing.T) {
+	_, _, err := ConvertTagsToLabels(map[string]string{
+		"Team": "a",
+		"team": "b",
+	})
+
+	assert.EqualError(t, err, `tags "Team" and "team" both convert to the GCE label key "team"`)
+}
+
+func TestConvertTagsToLabels_ReturnsError_If_KeyIsReserved(t *testing.T) {
+	_, _, err := ConvertTagsToLabels(map[string]string{
+		"node-tagger-managed-0": "a",
+	})
+
+	assert.EqualError(t, err, `tag "node-tagger-managed-0" converts to the GCE label key "node-tagger-managed-0", `+
+		`reserved for node-tagger`)
+}
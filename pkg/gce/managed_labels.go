@@ -0,0 +1,98 @@
+package gce
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// managedLabelPrefix starts the keys of the labels recording which labels node-tagger applied to an instance.
+	// GCE label values can not hold a list of label keys, so every managed key is recorded as a hash of it, and the
+	// hashes are spread over as many labels as needed: node-tagger-managed-0, node-tagger-managed-1 and so on.
+	managedLabelPrefix = "node-tagger-managed-"
+	// managedKeyHashLength is the length of the hexadecimal hash recording a managed key
+	managedKeyHashLength = 8
+	// managedKeyHashSeparator separates the hashes in the value of a managed label, and is never part of one
+	managedKeyHashSeparator = "_"
+	// managedKeyHashesPerLabel is the number of hashes fitting in the value of a single label
+	managedKeyHashesPerLabel = (MaxLabelLength + len(managedKeyHashSeparator)) /
+		(managedKeyHashLength + len(managedKeyHashSeparator))
+)
+
+// isManagedLabel tells whether the label records managed keys rather than being applied to the instance
+func isManagedLabel(key string) bool {
+	return strings.HasPrefix(key, managedLabelPrefix)
+}
+
+// splitManagedLabels separates the labels recording managed keys from the other labels of an instance
+func splitManagedLabels(labels map[string]string) (map[string]string, map[string]string) {
+	otherLabels := map[string]string{}
+	managedLabels := map[string]string{}
+
+	for key, value := range labels {
+		if isManagedLabel(key) {
+			managedLabels[key] = value
+		} else {
+			otherLabels[key] = value
+		}
+	}
+
+	return otherLabels, managedLabels
+}
+
+// parseManagedLabels returns the keys of the labels of the instance recorded in its managed labels
+func parseManagedLabels(otherLabels map[string]string, managedLabels map[string]string) []string {
+	hashes := map[string]bool{}
+
+	for _, value := range managedLabels {
+		for _, hash := range strings.Split(value, managedKeyHashSeparator) {
+			hashes[hash] = true
+		}
+	}
+
+	keys := []string{}
+
+	for key := range otherLabels {
+		if hashes[hashManagedKey(key)] {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// formatManagedLabels records the managed keys in as many managed labels as needed, none when there are no keys
+func formatManagedLabels(keys []string) map[string]string {
+	hashes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		hashes = append(hashes, hashManagedKey(key))
+	}
+
+	sort.Strings(hashes)
+
+	managedLabels := map[string]string{}
+
+	for i := 0; i < len(hashes); i += managedKeyHashesPerLabel {
+		end := i + managedKeyHashesPerLabel
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		managedLabels[managedLabelPrefix+strconv.Itoa(i/managedKeyHashesPerLabel)] =
+			strings.Join(hashes[i:end], managedKeyHashSeparator)
+	}
+
+	return managedLabels
+}
+
+func hashManagedKey(key string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return fmt.Sprintf("%0*x", managedKeyHashLength, hash.Sum32())
+}
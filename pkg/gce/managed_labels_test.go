@@ -0,0 +1,41 @@
+package gce
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatManagedLabels(t *testing.T) {
+	keys := []string{}
+	labels := map[string]string{"unmanaged": "value"}
+
+	for i := 0; i < 10; i++ {
+		key := "label-" + strconv.Itoa(i)
+		keys = append(keys, key)
+		labels[key] = "value"
+	}
+
+	managedLabels := formatManagedLabels(keys)
+
+	// Ten hashes need two labels
+	assert.Len(t, managedLabels, 2)
+
+	for key, value := range managedLabels {
+		assert.True(t, isManagedLabel(key))
+		assert.LessOrEqual(t, len(value), MaxLabelLength)
+		labels[key] = value
+	}
+
+	otherLabels, parsedManagedLabels := splitManagedLabels(labels)
+
+	assert.Equal(t, managedLabels, parsedManagedLabels)
+	assert.Len(t, otherLabels, 11)
+	assert.Equal(t, keys, parseManagedLabels(otherLabels, parsedManagedLabels))
+}
+
+func TestFormatManagedLabels_ReturnsNoLabels_If_NoKeysAreManaged(t *testing.T) {
+	assert.Empty(t, formatManagedLabels([]string{}))
+	assert.Empty(t, parseManagedLabels(map[string]string{"unmanaged": "value"}, map[string]string{}))
+}
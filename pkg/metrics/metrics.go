@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// UnknownProviderNodes is the number of nodes skipped because no provider is enabled for the scheme of their
+// ProviderID, by scheme
+var UnknownProviderNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "node_tagger_unknown_provider_nodes",
+	Help: "Number of nodes skipped because no provider is enabled for the scheme of their ProviderID, by scheme",
+}, []string{"scheme"})
+
+// UnknownRegionNodes counts the reconciles of AWS nodes skipped because the region of their instance cannot be
+// determined
+var UnknownRegionNodes = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "node_tagger_unknown_region_nodes_total",
+	Help: "Number of node reconciles skipped because the region of the node can not be determined",
+})
+
+// CredentialErrors counts the failures to get the credentials of the role assumed to tag the nodes of an AWS account
+var CredentialErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "node_tagger_credential_errors_total",
+	Help: "Number of failures to assume the role of an AWS account",
+}, []string{"account"})
+
+// TagCacheRequests counts the lookups of the known tags of the instances of AWS nodes, by whether the tags were found
+// in the cache and still desired, so the hit ratio is hit / (hit + miss)
+var TagCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "node_tagger_tag_cache_requests_total",
+	Help: "Number of lookups of the known tags of instances, by result: hit or miss",
+}, []string{"result"})
+
+// AWSThrottledCalls counts the calls to the AWS APIs throttled by AWS
+var AWSThrottledCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "node_tagger_aws_throttled_calls_total",
+	Help: "Number of calls to the AWS APIs throttled by AWS, by service and operation",
+}, []string{"service", "operation"})
+
+// AWSThrottleBackoff observes how long throttled calls to the AWS APIs wait before they are retried
+var AWSThrottleBackoff = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "node_tagger_aws_throttle_backoff_seconds",
+	Help:    "Time throttled calls to the AWS APIs wait before they are retried, by service",
+	Buckets: []float64{0.5, 1, 2, 4, 8, 16, 32},
+}, []string{"service"})
+
+// AWSRateLimiterWait observes how long the calls to the AWS APIs wait for the rate limiter of node-tagger
+var AWSRateLimiterWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "node_tagger_aws_rate_limiter_wait_seconds",
+	Help:    "Time calls to the AWS APIs wait for the rate limiter, by budget: describe or mutate",
+	Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10},
+}, []string{"budget"})
+
+// AWSAPILatency observes how long the calls to the AWS APIs take, for every attempt including the failed ones
+var AWSAPILatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "node_tagger_aws_api_request_duration_seconds",
+	Help:    "Time taken by every attempt of the calls to the AWS APIs, by service and operation",
+	Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+}, []string{"service", "operation"})
+
+// TaggingAttempts counts the attempts to tag the instances of nodes, by provider and by outcome: already-tagged,
+// tagged, not-found, ambiguous when more than one instance matches the node, or error
+var TaggingAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "node_tagger_tagging_attempts_total",
+	Help: "Number of attempts to tag the instances of nodes, by provider and outcome: already-tagged, tagged, " +
+		"not-found, ambiguous or error",
+}, []string{"provider", "outcome"})
+
+// NonCompliantNodes is the number of nodes whose instance does not have the desired tags because their last attempt
+// failed, by provider
+var NonCompliantNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "node_tagger_non_compliant_nodes",
+	Help: "Number of nodes whose instance does not have the desired tags because their last attempt failed, " +
+		"by provider",
+}, []string{"provider"})
+
+func init() {
+	// Register the metrics with the registry served by the manager
+	metrics.Registry.MustRegister(
+		UnknownProviderNodes,
+		UnknownRegionNodes,
+		CredentialErrors,
+		TagCacheRequests,
+		AWSThrottledCalls,
+		AWSThrottleBackoff,
+		AWSRateLimiterWait,
+		AWSAPILatency,
+		TaggingAttempts,
+		NonCompliantNodes,
+	)
+}
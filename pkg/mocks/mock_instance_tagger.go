@@ -1,14 +1,15 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/ouzi-dev/node-tagger/pkg/aws (interfaces: NodeTagger)
+// Source: github.com/ouzi-dev/node-tagger/pkg/provider (interfaces: NodeTagger)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
-	reflect "reflect"
-
 	gomock "github.com/golang/mock/gomock"
+	provider "github.com/ouzi-dev/node-tagger/pkg/provider"
+	tagging "github.com/ouzi-dev/node-tagger/pkg/tagging"
 	v1 "k8s.io/api/core/v1"
+	reflect "reflect"
 )
 
 // MockNodeTagger is a mock of NodeTagger interface
@@ -35,15 +36,16 @@ func (m *MockNodeTagger) EXPECT() *MockNodeTaggerMockRecorder {
 }
 
 // EnsureInstanceNodeHasTags mocks base method
-func (m *MockNodeTagger) EnsureInstanceNodeHasTags(arg0 *v1.Node, arg1 map[string]string) error {
+func (m *MockNodeTagger) EnsureInstanceNodeHasTags(arg0 *v1.Node, arg1 map[string]string, arg2 tagging.ConflictPolicies) (provider.TagResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnsureInstanceNodeHasTags", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "EnsureInstanceNodeHasTags", arg0, arg1, arg2)
+	ret0, _ := ret[0].(provider.TagResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // EnsureInstanceNodeHasTags indicates an expected call of EnsureInstanceNodeHasTags
-func (mr *MockNodeTaggerMockRecorder) EnsureInstanceNodeHasTags(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockNodeTaggerMockRecorder) EnsureInstanceNodeHasTags(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureInstanceNodeHasTags", reflect.TypeOf((*MockNodeTagger)(nil).EnsureInstanceNodeHasTags), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureInstanceNodeHasTags", reflect.TypeOf((*MockNodeTagger)(nil).EnsureInstanceNodeHasTags), arg0, arg1, arg2)
 }
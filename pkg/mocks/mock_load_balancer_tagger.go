@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ouzi-dev/node-tagger/pkg/aws (interfaces: LoadBalancerTagger)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	tagging "github.com/ouzi-dev/node-tagger/pkg/tagging"
+)
+
+// MockLoadBalancerTagger is a mock of LoadBalancerTagger interface
+type MockLoadBalancerTagger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoadBalancerTaggerMockRecorder
+}
+
+// MockLoadBalancerTaggerMockRecorder is the mock recorder for MockLoadBalancerTagger
+type MockLoadBalancerTaggerMockRecorder struct {
+	mock *MockLoadBalancerTagger
+}
+
+// NewMockLoadBalancerTagger creates a new mock instance
+func NewMockLoadBalancerTagger(ctrl *gomock.Controller) *MockLoadBalancerTagger {
+	mock := &MockLoadBalancerTagger{ctrl: ctrl}
+	mock.recorder = &MockLoadBalancerTaggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoadBalancerTagger) EXPECT() *MockLoadBalancerTaggerMockRecorder {
+	return m.recorder
+}
+
+// EnsureLoadBalancerHasTags mocks base method
+func (m *MockLoadBalancerTagger) EnsureLoadBalancerHasTags(arg0 string, arg1 map[string]string, arg2 tagging.ConflictPolicies) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureLoadBalancerHasTags", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureLoadBalancerHasTags indicates an expected call of EnsureLoadBalancerHasTags
+func (mr *MockLoadBalancerTaggerMockRecorder) EnsureLoadBalancerHasTags(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureLoadBalancerHasTags", reflect.TypeOf((*MockLoadBalancerTagger)(nil).EnsureLoadBalancerHasTags), arg0, arg1, arg2)
+}
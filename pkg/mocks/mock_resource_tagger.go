@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ouzi-dev/node-tagger/pkg/aws (interfaces: ResourceTagger)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	tagging "github.com/ouzi-dev/node-tagger/pkg/tagging"
+)
+
+// MockResourceTagger is a mock of ResourceTagger interface
+type MockResourceTagger struct {
+	ctrl     *gomock.Controller
+	recorder *MockResourceTaggerMockRecorder
+}
+
+// MockResourceTaggerMockRecorder is the mock recorder for MockResourceTagger
+type MockResourceTaggerMockRecorder struct {
+	mock *MockResourceTagger
+}
+
+// NewMockResourceTagger creates a new mock instance
+func NewMockResourceTagger(ctrl *gomock.Controller) *MockResourceTagger {
+	mock := &MockResourceTagger{ctrl: ctrl}
+	mock.recorder = &MockResourceTaggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockResourceTagger) EXPECT() *MockResourceTaggerMockRecorder {
+	return m.recorder
+}
+
+// EnsureResourceHasTags mocks base method
+func (m *MockResourceTagger) EnsureResourceHasTags(arg0 string, arg1 map[string]string, arg2 tagging.ConflictPolicies) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureResourceHasTags", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureResourceHasTags indicates an expected call of EnsureResourceHasTags
+func (mr *MockResourceTaggerMockRecorder) EnsureResourceHasTags(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureResourceHasTags", reflect.TypeOf((*MockResourceTagger)(nil).EnsureResourceHasTags), arg0, arg1, arg2)
+}
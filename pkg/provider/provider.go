@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ouzi-dev/node-tagger/pkg/tagging"
+	corev1 "k8s.io/api/core/v1"
+)
+
+//nolint
+//go:generate mockgen -package=mocks -destination ../mocks/mock_instance_tagger.go github.com/ouzi-dev/node-tagger/pkg/provider NodeTagger
+
+// NodeTagger tags the cloud instance a node runs on. Every cloud provider implements it.
+type NodeTagger interface {
+	EnsureInstanceNodeHasTags(node *corev1.Node, tags map[string]string,
+		conflictPolicies tagging.ConflictPolicies) (TagResult, error)
+}
+
+// Resyncer is implemented by the NodeTaggers remembering the tags they last saw on the instances
+type Resyncer interface {
+	// Resync forgets the remembered tags, so the next reconcile of every node reads them from the cloud provider
+	Resync()
+}
+
+// PermissionDeniedError is returned when the credentials of node-tagger are not allowed to read or tag the instance
+// of a node
+type PermissionDeniedError struct {
+	Err error
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermissionDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// TagResult describes the tags a NodeTagger wrote to the instance of a node and to the resources tagged along with it.
+// It is empty when everything already had the desired tags.
+type TagResult struct {
+	// SetKeys are the sorted keys of the tags created or overwritten
+	SetKeys []string
+	// RemovedKeys are the sorted keys of the tags removed because they are no longer desired
+	RemovedKeys []string
+}
+
+// NewTagResult records the keys of the tags written to a resource of a node
+func NewTagResult(setKeys []string, removedKeys []string) TagResult {
+	return TagResult{
+		SetKeys:     mergeKeys(nil, setKeys),
+		RemovedKeys: mergeKeys(nil, removedKeys),
+	}
+}
+
+// Changed reports whether any tag was written
+func (r TagResult) Changed() bool {
+	return len(r.SetKeys) > 0 || len(r.RemovedKeys) > 0
+}
+
+// Keys returns the sorted keys of every tag written
+func (r TagResult) Keys() []string {
+	return mergeKeys(r.SetKeys, r.RemovedKeys)
+}
+
+// Add records the keys of the tags written to another resource of the node
+func (r *TagResult) Add(other TagResult) {
+	r.SetKeys = mergeKeys(r.SetKeys, other.SetKeys)
+	r.RemovedKeys = mergeKeys(r.RemovedKeys, other.RemovedKeys)
+}
+
+// mergeKeys returns the sorted union of the keys, or nil if there are none
+func mergeKeys(keys []string, otherKeys []string) []string {
+	unique := map[string]bool{}
+	for _, key := range keys {
+		unique[key] = true
+	}
+
+	for _, key := range otherKeys {
+		unique[key] = true
+	}
+
+	if len(unique) == 0 {
+		return nil
+	}
+
+	merged := make([]string, 0, len(unique))
+	for key := range unique {
+		merged = append(merged, key)
+	}
+
+	sort.Strings(merged)
+
+	return merged
+}
+
+// Registry holds the NodeTagger of every enabled cloud provider, keyed by the scheme of the ProviderIDs of the nodes
+// running on it, such as aws for aws:///eu-west-1a/i-0123456789abcdef0
+type Registry struct {
+	nodeTaggers map[string]NodeTagger
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		nodeTaggers: map[string]NodeTagger{},
+	}
+}
+
+// Register makes the NodeTagger handle the nodes whose ProviderID has the given scheme
+func (r *Registry) Register(scheme string, nodeTagger NodeTagger) {
+	r.nodeTaggers[scheme] = nodeTagger
+}
+
+// Get returns the NodeTagger registered for the scheme, if any
+func (r *Registry) Get(scheme string) (NodeTagger, bool) {
+	nodeTagger, found := r.nodeTaggers[scheme]
+	return nodeTagger, found
+}
+
+// Resync makes every registered NodeTagger remembering tags read them from the cloud provider again
+func (r *Registry) Resync() {
+	for _, nodeTagger := range r.nodeTaggers {
+		if resyncer, ok := nodeTagger.(Resyncer); ok {
+			resyncer.Resync()
+		}
+	}
+}
+
+// Scheme returns the scheme of a ProviderID, or an empty string if the ProviderID has none, for example because the
+// cloud provider did not initialise the node yet
+func Scheme(providerID string) string {
+	separator := strings.Index(providerID, "://")
+	if separator < 0 {
+		return ""
+	}
+
+	return providerID[:separator]
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheme(t *testing.T) {
+	tests := []struct {
+		providerID string
+		expected   string
+	}{
+		{providerID: "aws:///eu-west-1a/i-0123456789abcdef0", expected: "aws"},
+		{providerID: "gce://project/europe-west1-b/instance", expected: "gce"},
+		{providerID: "azure:///subscriptions/id/resourceGroups/group/providers/Microsoft.Compute/virtualMachines/vm",
+			expected: "azure"},
+		{providerID: "kind://docker/kind/kind-control-plane", expected: "kind"},
+		{providerID: "i-0123456789abcdef0", expected: ""},
+		{providerID: "", expected: ""},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.providerID, func(t *testing.T) {
+			assert.Equal(t, testData.expected, Scheme(testData.providerID))
+		})
+	}
+}
+
+type nodeTaggerStub struct {
+	NodeTagger
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	awsNodeTagger := &nodeTaggerStub{}
+
+	registry.Register("aws", awsNodeTagger)
+
+	nodeTagger, found := registry.Get("aws")
+	assert.True(t, found)
+	assert.Same(t, awsNodeTagger, nodeTagger)
+
+	_, found = registry.Get("gce")
+	assert.False(t, found)
+}
+
+type resyncingNodeTaggerStub struct {
+	NodeTagger
+	resyncs int
+}
+
+func (r *resyncingNodeTaggerStub) Resync() {
+	r.resyncs++
+}
+
+func TestRegistry_Resync(t *testing.T) {
+	registry := NewRegistry()
+	awsNodeTagger := &resyncingNodeTaggerStub{}
+
+	registry.Register("aws", awsNodeTagger)
+	registry.Register("gce", &nodeTaggerStub{})
+
+	registry.Resync()
+
+	assert.Equal(t, 1, awsNodeTagger.resyncs)
+}
+
+func TestTagResult_Add(t *testing.T) {
+	result := TagResult{}
+	assert.False(t, result.Changed())
+
+	result.Add(NewTagResult([]string{"b", "a"}, nil))
+	result.Add(NewTagResult([]string{"a", "c"}, []string{"old"}))
+	result.Add(TagResult{})
+
+	assert.True(t, result.Changed())
+	assert.Equal(t, NewTagResult([]string{"a", "b", "c"}, []string{"old"}), result)
+	assert.Equal(t, []string{"a", "b", "c", "old"}, result.Keys())
+}
@@ -0,0 +1,20 @@
+package tagging
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ParseTagsAnnotation parses the value of a tags annotation, a JSON object of tag keys to tag values such as
+// {"team": "platform", "cost-center": "1234"}
+func ParseTagsAnnotation(value string) (map[string]string, error) {
+	tags := map[string]string{}
+
+	err := json.Unmarshal([]byte(value), &tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "tags annotation must be a JSON object of string keys to string values")
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,21 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagsAnnotation(t *testing.T) {
+	tags, err := ParseTagsAnnotation(`{"tag1": "value1", "tag2": "{{ .Name }}"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tag1": "value1", "tag2": "{{ .Name }}"}, tags)
+
+	tags, err = ParseTagsAnnotation(`tag1=value1`)
+	assert.Error(t, err)
+	assert.Nil(t, tags)
+
+	tags, err = ParseTagsAnnotation(`{"tag1": 1}`)
+	assert.Error(t, err)
+	assert.Nil(t, tags)
+}
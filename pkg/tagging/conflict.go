@@ -0,0 +1,67 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConflictPolicy decides what happens when a resource already has a tag with a different value than the desired
+// one, and that tag was not applied by node-tagger
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyOverwrite replaces the existing value with the desired one
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+	// ConflictPolicyKeepExisting leaves the existing value in place
+	ConflictPolicyKeepExisting ConflictPolicy = "keep-existing"
+	// ConflictPolicyFail does not tag the resource at all and reports the conflict
+	ConflictPolicyFail ConflictPolicy = "fail"
+)
+
+// ConflictPolicies are the conflict policies of the desired tags by key
+type ConflictPolicies map[string]ConflictPolicy
+
+// For returns the conflict policy of the tag with the given key, defaulting to overwrite
+func (p ConflictPolicies) For(key string) ConflictPolicy {
+	if policy, found := p[key]; found && policy != "" {
+		return policy
+	}
+
+	return ConflictPolicyOverwrite
+}
+
+// ParseConflictPolicy parses the name of a conflict policy
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case ConflictPolicyOverwrite, ConflictPolicyKeepExisting, ConflictPolicyFail:
+		return policy, nil
+	default:
+		return "", errors.Errorf("invalid conflict policy %q, must be one of %s, %s or %s",
+			value, ConflictPolicyOverwrite, ConflictPolicyKeepExisting, ConflictPolicyFail)
+	}
+}
+
+// Conflict is a desired tag whose key already exists on a resource with a different value
+type Conflict struct {
+	Key           string
+	ExistingValue string
+	DesiredValue  string
+}
+
+// ConflictError is returned when a resource has tags conflicting with desired tags whose conflict policy is fail
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	conflicts := make([]string, 0, len(e.Conflicts))
+
+	for _, conflict := range e.Conflicts {
+		conflicts = append(conflicts, fmt.Sprintf("%s (existing value %q, desired value %q)",
+			conflict.Key, conflict.ExistingValue, conflict.DesiredValue))
+	}
+
+	return "conflicting tags: " + strings.Join(conflicts, ", ")
+}
@@ -0,0 +1,41 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, value := range []string{"overwrite", "keep-existing", "fail"} {
+		policy, err := ParseConflictPolicy(value)
+		assert.NoError(t, err)
+		assert.Equal(t, ConflictPolicy(value), policy)
+	}
+
+	_, err := ParseConflictPolicy("keep")
+	assert.EqualError(t, err, `invalid conflict policy "keep", must be one of overwrite, keep-existing or fail`)
+}
+
+func TestConflictPolicies_For(t *testing.T) {
+	policies := ConflictPolicies{
+		"tag1": ConflictPolicyFail,
+		"tag2": "",
+	}
+
+	assert.Equal(t, ConflictPolicyFail, policies.For("tag1"))
+	assert.Equal(t, ConflictPolicyOverwrite, policies.For("tag2"))
+	assert.Equal(t, ConflictPolicyOverwrite, policies.For("tag3"))
+}
+
+func TestConflictError_Error(t *testing.T) {
+	err := &ConflictError{
+		Conflicts: []Conflict{
+			{Key: "tag1", ExistingValue: "terraform", DesiredValue: "node-tagger"},
+			{Key: "tag2", ExistingValue: "", DesiredValue: "value2"},
+		},
+	}
+
+	assert.EqualError(t, err, `conflicting tags: tag1 (existing value "terraform", desired value "node-tagger"), `+
+		`tag2 (existing value "", desired value "value2")`)
+}
@@ -0,0 +1,19 @@
+package tagging
+
+import (
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a string identifying a set of tags regardless of the order of its keys, so controllers can
+// tell whether the tags they applied to a resource before are still the desired ones
+func Fingerprint(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "\n")
+}
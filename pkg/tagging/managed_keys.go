@@ -0,0 +1,107 @@
+package tagging
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+)
+
+// ManagedKeysTooLongError is returned when a key of the tags node-tagger applies to a resource does not fit in the
+// value of the tag recording it
+type ManagedKeysTooLongError struct {
+	// Tag is the tag recording the keys
+	Tag string
+	// Length is the length of the keys once joined
+	Length int
+	// Max is the maximum length of the value of the tag
+	Max int
+}
+
+func (e *ManagedKeysTooLongError) Error() string {
+	return fmt.Sprintf("the keys are %d characters long once joined, too long to be recorded in the %s tag whose "+
+		"value is at most %d characters: this is a limit of node-tagger, not of the cloud provider; use fewer or "+
+		"shorter keys", e.Length, e.Tag, e.Max)
+}
+
+// IsManagedKeysTag tells whether the key is the managed keys tag or one of the numbered tags its keys overflow into:
+// <tag>-1, <tag>-2 and so on
+func IsManagedKeysTag(managedKeysTag string, key string) bool {
+	if key == managedKeysTag {
+		return true
+	}
+
+	if !strings.HasPrefix(key, managedKeysTag+"-") {
+		return false
+	}
+
+	suffix := strings.TrimPrefix(key, managedKeysTag+"-")
+	number, err := strconv.Atoi(suffix)
+
+	return err == nil && number > 0 && strconv.Itoa(number) == suffix
+}
+
+// ParseManagedKeys returns the sorted keys recorded in the managed keys tag and in the numbered tags its keys
+// overflow into, among the tags of a resource
+func ParseManagedKeys(managedKeysTag string, tags map[string]string) []string {
+	keys := []string{}
+
+	for key, value := range tags {
+		if !IsManagedKeysTag(managedKeysTag, key) || value == "" {
+			continue
+		}
+
+		keys = append(keys, strings.Split(value, constants.ManagedKeysSeparator)...)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// FormatManagedKeys records the keys in the managed keys tag, and in as many numbered tags as needed for every value
+// to fit in MaxValueLength. No keys need no tag at all.
+func FormatManagedKeys(managedKeysTag string, keys []string) (map[string]string, error) {
+	sortedKeys := append([]string{}, keys...)
+	sort.Strings(sortedKeys)
+
+	tags := map[string]string{}
+	values := []string{}
+	value := ""
+
+	for _, key := range sortedKeys {
+		if len(key) > MaxValueLength {
+			return nil, &ManagedKeysTooLongError{Tag: managedKeysTag, Length: len(key), Max: MaxValueLength}
+		}
+
+		switch {
+		case value == "":
+			value = key
+		case len(value)+len(constants.ManagedKeysSeparator)+len(key) <= MaxValueLength:
+			value += constants.ManagedKeysSeparator + key
+		default:
+			values = append(values, value)
+			value = key
+		}
+	}
+
+	if value != "" {
+		values = append(values, value)
+	}
+
+	for i, value := range values {
+		tags[managedKeysTagName(managedKeysTag, i)] = value
+	}
+
+	return tags, nil
+}
+
+func managedKeysTagName(managedKeysTag string, i int) string {
+	if i == 0 {
+		return managedKeysTag
+	}
+
+	return managedKeysTag + "-" + strconv.Itoa(i)
+}
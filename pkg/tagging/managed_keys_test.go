@@ -0,0 +1,97 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagedKeysTooLongError_Error(t *testing.T) {
+	err := &ManagedKeysTooLongError{Tag: constants.ManagedKeysTag, Length: 257, Max: 256}
+
+	assert.EqualError(t, err, "the keys are 257 characters long once joined, too long to be recorded in the "+
+		"node-tagger.ouzi.dev/managed-keys tag whose value is at most 256 characters: this is a limit of "+
+		"node-tagger, not of the cloud provider; use fewer or shorter keys")
+}
+
+func TestIsManagedKeysTag(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{key: constants.ManagedKeysTag, expected: true},
+		{key: constants.ManagedKeysTag + "-1", expected: true},
+		{key: constants.ManagedKeysTag + "-12", expected: true},
+		{key: constants.ManagedKeysTag + "-0", expected: false},
+		{key: constants.ManagedKeysTag + "-01", expected: false},
+		{key: constants.ManagedKeysTag + "-a", expected: false},
+		{key: constants.ManagedKeysTag + "s", expected: false},
+		{key: constants.VolumeManagedKeysTag, expected: false},
+		{key: "team", expected: false},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.key, func(t *testing.T) {
+			assert.Equal(t, testData.expected, IsManagedKeysTag(constants.ManagedKeysTag, testData.key))
+		})
+	}
+}
+
+func TestFormatManagedKeys(t *testing.T) {
+	tags, err := FormatManagedKeys(constants.ManagedKeysTag, []string{"tag2", "tag1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{constants.ManagedKeysTag: "tag1,tag2"}, tags)
+
+	tags, err = FormatManagedKeys(constants.ManagedKeysTag, []string{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{}, tags)
+}
+
+func TestFormatManagedKeys_SpreadsLongKeysOverNumberedTags(t *testing.T) {
+	keys := []string{}
+	for i := 0; i < 49; i++ {
+		keys = append(keys, fmt.Sprintf("%02d%s", i, strings.Repeat("k", 126)))
+	}
+
+	tags, err := FormatManagedKeys(constants.ManagedKeysTag, keys)
+
+	assert.NoError(t, err)
+	// Only one key of 128 characters fits in a value of 256 characters along with the separator
+	assert.Len(t, tags, 49)
+	assert.Equal(t, keys[0], tags[constants.ManagedKeysTag])
+	assert.Equal(t, keys[48], tags[constants.ManagedKeysTag+"-48"])
+
+	for key, value := range tags {
+		assert.True(t, IsManagedKeysTag(constants.ManagedKeysTag, key), key)
+		assert.True(t, len(value) <= MaxValueLength, key)
+	}
+
+	assert.Equal(t, keys, ParseManagedKeys(constants.ManagedKeysTag, tags))
+}
+
+func TestFormatManagedKeys_ReturnsError_If_KeyTooLong(t *testing.T) {
+	_, err := FormatManagedKeys(constants.ManagedKeysTag, []string{strings.Repeat("k", 257)})
+
+	assert.Equal(t, &ManagedKeysTooLongError{Tag: constants.ManagedKeysTag, Length: 257, Max: 256}, err)
+}
+
+func TestParseManagedKeys(t *testing.T) {
+	tags := map[string]string{
+		constants.ManagedKeysTag:        "tag1,tag3",
+		constants.ManagedKeysTag + "-1": "tag2",
+		constants.ManagedKeysTag + "-x": "ignored",
+		constants.VolumeManagedKeysTag:  "claim",
+		"tag1":                          "value",
+	}
+
+	assert.Equal(t, []string{"tag1", "tag2", "tag3"}, ParseManagedKeys(constants.ManagedKeysTag, tags))
+	assert.Equal(t, []string{},
+		ParseManagedKeys(constants.ManagedKeysTag, map[string]string{constants.ManagedKeysTag: ""}))
+}
@@ -0,0 +1,101 @@
+package tagging
+
+import "sort"
+
+// MergeResult holds the tags of a resource once the desired ones are merged into its existing ones
+type MergeResult struct {
+	// Tags are every tag of the resource, to be written as a whole
+	Tags map[string]string
+	// ManagedKeys are the sorted keys of the tags node-tagger applied, to be recorded along with the tags
+	ManagedKeys []string
+	// SetKeys are the sorted keys of the tags whose value changed
+	SetKeys []string
+	// RemovedKeys are the sorted keys of the managed tags that are no longer desired
+	RemovedKeys []string
+}
+
+// Changed tells whether any tag of the resource changed. The recorded managed keys can change on their own.
+func (m *MergeResult) Changed() bool {
+	return len(m.SetKeys) > 0 || len(m.RemovedKeys) > 0
+}
+
+// Merge adds the desired tags to the existing ones of a resource, for the providers whose tags are written as a
+// whole. managedKeys are the keys of the tags node-tagger applied to the resource before: only those are removed once
+// no longer desired, and a key is only recorded when node-tagger creates the tag, so tags applied by anyone else are
+// left untouched. An existing tag with a different value that node-tagger did not apply is a conflict, resolved with
+// the conflict policy of its key.
+func Merge(existing map[string]string, managedKeys []string, desired map[string]string,
+	conflictPolicies ConflictPolicies) (*MergeResult, error) {
+	managed := map[string]bool{}
+	for _, key := range managedKeys {
+		managed[key] = true
+	}
+
+	result := &MergeResult{
+		Tags:        map[string]string{},
+		ManagedKeys: []string{},
+		SetKeys:     []string{},
+		RemovedKeys: []string{},
+	}
+
+	for key, value := range existing {
+		result.Tags[key] = value
+	}
+
+	conflicts := []Conflict{}
+
+	for _, key := range sortedKeys(desired) {
+		value := desired[key]
+		existingValue, found := existing[key]
+
+		if found && existingValue != value && !managed[key] {
+			switch conflictPolicies.For(key) {
+			case ConflictPolicyKeepExisting:
+				continue
+			case ConflictPolicyFail:
+				conflicts = append(conflicts, Conflict{Key: key, ExistingValue: existingValue, DesiredValue: value})
+				continue
+			}
+		}
+
+		// Tags applied by anyone else stay theirs, even when they are overwritten
+		if !found || managed[key] {
+			result.ManagedKeys = append(result.ManagedKeys, key)
+		}
+
+		if !found || existingValue != value {
+			result.Tags[key] = value
+			result.SetKeys = append(result.SetKeys, key)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	for key := range managed {
+		if _, stillDesired := desired[key]; stillDesired {
+			continue
+		}
+
+		if _, found := existing[key]; found {
+			delete(result.Tags, key)
+			result.RemovedKeys = append(result.RemovedKeys, key)
+		}
+	}
+
+	sort.Strings(result.RemovedKeys)
+
+	return result, nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
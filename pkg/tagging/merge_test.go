@@ -0,0 +1,92 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	existing := map[string]string{
+		"unmanaged": "value",
+		"same":      "value",
+		"overwrite": "existing",
+		"keep":      "existing",
+		"changed":   "old",
+		"removed":   "value",
+	}
+	managedKeys := []string{"changed", "removed", "gone"}
+	desired := map[string]string{
+		"same":      "value",
+		"overwrite": "desired",
+		"keep":      "desired",
+		"changed":   "new",
+		"new":       "desired",
+	}
+
+	result, err := Merge(existing, managedKeys, desired, ConflictPolicies{
+		"keep":    ConflictPolicyKeepExisting,
+		"changed": ConflictPolicyFail,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &MergeResult{
+		Tags: map[string]string{
+			"unmanaged": "value",
+			"same":      "value",
+			"overwrite": "desired",
+			"keep":      "existing",
+			"changed":   "new",
+			"new":       "desired",
+		},
+		// Only the tags node-tagger created are managed, not the ones it found or overwrote
+		ManagedKeys: []string{"changed", "new"},
+		SetKeys:     []string{"changed", "new", "overwrite"},
+		RemovedKeys: []string{"removed"},
+	}, result)
+	assert.True(t, result.Changed())
+	assert.Equal(t, "value", existing["unmanaged"])
+	assert.Equal(t, "existing", existing["overwrite"])
+}
+
+func TestMerge_ReportsUnchangedTags(t *testing.T) {
+	existing := map[string]string{"same": "value", "keep": "existing", "managed": "value"}
+
+	result, err := Merge(existing, []string{"managed"},
+		map[string]string{"same": "value", "keep": "desired", "managed": "value"},
+		ConflictPolicies{"keep": ConflictPolicyKeepExisting})
+
+	assert.NoError(t, err)
+	assert.False(t, result.Changed())
+	assert.Equal(t, existing, result.Tags)
+	assert.Equal(t, []string{"managed"}, result.ManagedKeys)
+}
+
+func TestMerge_KeepsExistingTagWithTheDesiredValue_When_NoLongerDesired(t *testing.T) {
+	existing := map[string]string{"team": "platform"}
+
+	result, err := Merge(existing, []string{}, map[string]string{"team": "platform", "env": "prod"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"env"}, result.ManagedKeys)
+
+	result, err = Merge(result.Tags, result.ManagedKeys, map[string]string{}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "platform"}, result.Tags)
+	assert.Equal(t, []string{"env"}, result.RemovedKeys)
+	assert.Empty(t, result.ManagedKeys)
+}
+
+func TestMerge_ReturnsConflictError_If_PolicyIsFail(t *testing.T) {
+	_, err := Merge(map[string]string{"b": "existing", "a": "existing"}, nil,
+		map[string]string{"a": "desired", "b": "desired", "c": "desired"},
+		ConflictPolicies{"a": ConflictPolicyFail, "b": ConflictPolicyFail})
+
+	assert.Equal(t, &ConflictError{
+		Conflicts: []Conflict{
+			{Key: "a", ExistingValue: "existing", DesiredValue: "desired"},
+			{Key: "b", ExistingValue: "existing", DesiredValue: "desired"},
+		},
+	}, err)
+}
@@ -0,0 +1,138 @@
+package tagging
+
+import (
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ObjectData is the data the tag value templates are rendered against for a namespaced object, such as a
+// PersistentVolumeClaim or a Service, for example {{ .Namespace }} or {{ .NamespaceLabels "team" }}
+type ObjectData struct {
+	kind      string
+	object    metav1.Object
+	namespace *corev1.Namespace
+}
+
+// NewObjectData returns the template data of the object and its namespace. The kind names the object in errors.
+func NewObjectData(kind string, object metav1.Object, namespace *corev1.Namespace) ObjectData {
+	return ObjectData{kind: kind, object: object, namespace: namespace}
+}
+
+// Name returns the name of the object
+func (d ObjectData) Name() string {
+	return d.object.GetName()
+}
+
+// Namespace returns the namespace of the object
+func (d ObjectData) Namespace() string {
+	return d.object.GetNamespace()
+}
+
+// Labels returns the value of the object label with the given key. It fails if the object does not have the label
+func (d ObjectData) Labels(key string) (string, error) {
+	value, found := d.object.GetLabels()[key]
+	if !found {
+		return "", errors.Errorf("%s has no label %q", d.describe(), key)
+	}
+
+	return value, nil
+}
+
+// Annotations returns the value of the object annotation with the given key. It fails if the object does not have
+// the annotation
+func (d ObjectData) Annotations(key string) (string, error) {
+	value, found := d.object.GetAnnotations()[key]
+	if !found {
+		return "", errors.Errorf("%s has no annotation %q", d.describe(), key)
+	}
+
+	return value, nil
+}
+
+// NamespaceLabels returns the value of the label with the given key of the namespace of the object. It fails if the
+// namespace does not have the label
+func (d ObjectData) NamespaceLabels(key string) (string, error) {
+	value, found := d.namespace.Labels[key]
+	if !found {
+		return "", errors.Errorf("namespace %s has no label %q", d.namespace.Name, key)
+	}
+
+	return value, nil
+}
+
+// OptionalLabels returns the value of the object label with the given key, empty if the object does not have the
+// label
+func (d ObjectData) OptionalLabels(key string) string {
+	return d.object.GetLabels()[key]
+}
+
+// OptionalAnnotations returns the value of the object annotation with the given key, empty if the object does not
+// have the annotation
+func (d ObjectData) OptionalAnnotations(key string) string {
+	return d.object.GetAnnotations()[key]
+}
+
+// OptionalNamespaceLabels returns the value of the label with the given key of the namespace of the object, empty if
+// the namespace does not have the label
+func (d ObjectData) OptionalNamespaceLabels(key string) string {
+	return d.namespace.Labels[key]
+}
+
+func (d ObjectData) describe() string {
+	return d.kind + " " + d.object.GetNamespace() + "/" + d.object.GetName()
+}
+
+// ClaimTags computes the tags of the storage behind a claim. The templates are applied first, followed by the tags
+// annotation of the namespace and finally by the tags annotation of the claim, so when two sources set the same key
+// the last one wins. The result is rendered against the claim and validated.
+func ClaimTags(templates map[string]string, claim *corev1.PersistentVolumeClaim,
+	namespace *corev1.Namespace) (map[string]string, error) {
+	return ObjectTags(NewObjectData("claim", claim, namespace), templates)
+}
+
+// ServiceTags computes the tags of the load balancer of a service, in the same order as ClaimTags
+func ServiceTags(templates map[string]string, service *corev1.Service,
+	namespace *corev1.Namespace) (map[string]string, error) {
+	return ObjectTags(NewObjectData("service", service, namespace), templates)
+}
+
+// ObjectTags computes the tags of the cloud resource behind a namespaced object. The templates are applied first,
+// followed by the tags annotation of the namespace and finally by the tags annotation of the object, so when two
+// sources set the same key the last one wins. The result is rendered against the object and validated.
+func ObjectTags(data ObjectData, templates map[string]string) (map[string]string, error) {
+	tags := map[string]string{}
+
+	for key, value := range templates {
+		tags[key] = value
+	}
+
+	for _, annotations := range []map[string]string{data.namespace.Annotations, data.object.GetAnnotations()} {
+		annotation, found := annotations[constants.TagsAnnotation]
+		if !found {
+			continue
+		}
+
+		annotationTags, err := ParseTagsAnnotation(annotation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s annotation for %s", constants.TagsAnnotation, data.describe())
+		}
+
+		for key, value := range annotationTags {
+			tags[key] = value
+		}
+	}
+
+	tags, err := Render(tags, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = Validate(tags)
+	if err != nil {
+		return nil, errors.Wrap(err, data.describe())
+	}
+
+	return tags, nil
+}
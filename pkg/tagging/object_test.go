@@ -0,0 +1,135 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var claimNamespace = &corev1.Namespace{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "team-a",
+		Labels: map[string]string{
+			"team": "a",
+		},
+		Annotations: map[string]string{
+			constants.TagsAnnotation: `{"cost-center": "namespace", "owner": "namespace"}`,
+		},
+	},
+}
+
+var claim = &corev1.PersistentVolumeClaim{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "data",
+		Namespace: "team-a",
+		Labels: map[string]string{
+			"app": "database",
+		},
+		Annotations: map[string]string{
+			constants.TagsAnnotation: `{"owner": "claim"}`,
+		},
+	},
+}
+
+func TestClaimTags(t *testing.T) {
+	templates := map[string]string{
+		"app":         `{{ .Labels "app" }}`,
+		"claim":       "{{ .Namespace }}/{{ .Name }}",
+		"cost-center": "templates",
+		"team":        `{{ .NamespaceLabels "team" | upper }}`,
+	}
+
+	tags, err := ClaimTags(templates, claim, claimNamespace)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app":         "database",
+		"claim":       "team-a/data",
+		"cost-center": "namespace",
+		"owner":       "claim",
+		"team":        "A",
+	}, tags)
+}
+
+func TestClaimTags_ReturnsError_If_TemplateReferencesMissingData(t *testing.T) {
+	templates := map[string]string{
+		"team": `{{ .Labels "team" }}`,
+	}
+
+	_, err := ClaimTags(templates, claim, claimNamespace)
+
+	assert.EqualError(t, err, `failed to render template for tag "team": template: team:1:3: executing "team" `+
+		`at <.Labels>: error calling Labels: claim team-a/data has no label "team"`)
+}
+
+func TestClaimTags_RendersMissingOptionalDataEmpty(t *testing.T) {
+	templates := map[string]string{
+		"team":        `{{ .OptionalLabels "team" | default "none" }}`,
+		"contact":     `{{ .OptionalAnnotations "contact" | default "none" }}`,
+		"environment": `{{ .OptionalNamespaceLabels "environment" | default "none" }}`,
+	}
+
+	tags, err := ClaimTags(templates, claim, claimNamespace)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "none", tags["team"])
+	assert.Equal(t, "none", tags["contact"])
+	assert.Equal(t, "none", tags["environment"])
+}
+
+func TestClaimTags_ReturnsError_If_AnnotationIsInvalid(t *testing.T) {
+	invalidClaim := claim.DeepCopy()
+	invalidClaim.Annotations[constants.TagsAnnotation] = "owner=claim"
+
+	_, err := ClaimTags(map[string]string{}, invalidClaim, claimNamespace)
+
+	assert.EqualError(t, err, "invalid node-tagger.ouzi.dev/tags annotation for claim team-a/data: tags annotation "+
+		"must be a JSON object of string keys to string values: invalid character 'o' looking for beginning of value")
+}
+
+func TestClaimTags_ReturnsError_If_TagsAreInvalid(t *testing.T) {
+	_, err := ClaimTags(map[string]string{"aws:tag": "value"}, claim, claimNamespace)
+
+	assert.EqualError(t, err, `claim team-a/data: invalid tags: key "aws:tag" uses the reserved aws: prefix`)
+}
+
+func TestServiceTags(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "team-a",
+			Labels: map[string]string{
+				"app": "nginx",
+			},
+			Annotations: map[string]string{
+				constants.TagsAnnotation: `{"owner": "service"}`,
+			},
+		},
+	}
+
+	templates := map[string]string{
+		"app":     `{{ .Labels "app" }}`,
+		"service": "{{ .Namespace }}/{{ .Name }}",
+		"ingress": `{{ .Annotations "ingress" }}`,
+	}
+
+	_, err := ServiceTags(templates, service, claimNamespace)
+
+	assert.EqualError(t, err, `failed to render template for tag "ingress": template: ingress:1:3: executing `+
+		`"ingress" at <.Annotations>: error calling Annotations: service team-a/ingress has no annotation "ingress"`)
+
+	delete(templates, "ingress")
+
+	tags, err := ServiceTags(templates, service, claimNamespace)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app":         "nginx",
+		"cost-center": "namespace",
+		"owner":       "service",
+		"service":     "team-a/ingress",
+	}, tags)
+}
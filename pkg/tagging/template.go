@@ -0,0 +1,174 @@
+package tagging
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	instanceTypeLabel     = "node.kubernetes.io/instance-type"
+	betaInstanceTypeLabel = "beta.kubernetes.io/instance-type"
+)
+
+// templateFuncs are the functions available to the tag value templates on top of the text/template builtins.
+// The value being transformed is always the last argument so they can be used at the end of a pipeline.
+var templateFuncs = template.FuncMap{
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"truncate": truncate,
+	"default":  defaultValue,
+	"replace":  replace,
+}
+
+// NodeData is the data the tag value templates are rendered against for a node, for example
+// {{ .Name }} or {{ .Labels "topology.kubernetes.io/zone" }}
+type NodeData struct {
+	node *corev1.Node
+}
+
+// NewNodeData returns the template data of the node
+func NewNodeData(node *corev1.Node) NodeData {
+	return NodeData{node: node}
+}
+
+// Name returns the name of the node
+func (d NodeData) Name() string {
+	return d.node.Name
+}
+
+// Labels returns the value of the node label with the given key. It fails if the node does not have the label
+func (d NodeData) Labels(key string) (string, error) {
+	value, found := d.node.Labels[key]
+	if !found {
+		return "", errors.Errorf("node %s has no label %q", d.node.Name, key)
+	}
+
+	return value, nil
+}
+
+// Annotations returns the value of the node annotation with the given key. It fails if the node does not have the
+// annotation
+func (d NodeData) Annotations(key string) (string, error) {
+	value, found := d.node.Annotations[key]
+	if !found {
+		return "", errors.Errorf("node %s has no annotation %q", d.node.Name, key)
+	}
+
+	return value, nil
+}
+
+// OptionalLabels returns the value of the node label with the given key, empty if the node does not have the label,
+// so a fallback can be set with default, for example {{ .OptionalLabels "team" | default "none" }}
+func (d NodeData) OptionalLabels(key string) string {
+	return d.node.Labels[key]
+}
+
+// OptionalAnnotations returns the value of the node annotation with the given key, empty if the node does not have
+// the annotation
+func (d NodeData) OptionalAnnotations(key string) string {
+	return d.node.Annotations[key]
+}
+
+// InstanceType returns the instance type of the node from its well known labels
+func (d NodeData) InstanceType() (string, error) {
+	for _, label := range []string{instanceTypeLabel, betaInstanceTypeLabel} {
+		if value, found := d.node.Labels[label]; found {
+			return value, nil
+		}
+	}
+
+	return "", errors.Errorf("node %s has no instance type label", d.node.Name)
+}
+
+// KubeletVersion returns the kubelet version reported by the node
+func (d NodeData) KubeletVersion() string {
+	return d.node.Status.NodeInfo.KubeletVersion
+}
+
+// NodeInfo returns the system information reported by the node, for example {{ .NodeInfo.OSImage }}
+func (d NodeData) NodeInfo() corev1.NodeSystemInfo {
+	return d.node.Status.NodeInfo
+}
+
+// Render renders every tag value as a template against data and returns the resulting tags. The tags are rendered
+// in alphabetical order of key and the first failure is returned naming the tag it belongs to.
+func Render(tags map[string]string, data interface{}) (map[string]string, error) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	rendered := make(map[string]string, len(tags))
+
+	for _, key := range keys {
+		value, err := renderValue(key, tags[key], data)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered[key] = value
+	}
+
+	return rendered, nil
+}
+
+func renderValue(key string, value string, data interface{}) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := parseTemplate(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := &bytes.Buffer{}
+
+	err = tmpl.Execute(buffer, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to render template for tag %q", key)
+	}
+
+	return buffer.String(), nil
+}
+
+func parseTemplate(key string, value string) (*template.Template, error) {
+	tmpl, err := template.New(key).Option("missingkey=error").Funcs(templateFuncs).Parse(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid template for tag %q", key)
+	}
+
+	return tmpl, nil
+}
+
+func truncate(length int, value string) (string, error) {
+	if length < 0 {
+		return "", errors.Errorf("cannot truncate to negative length %d", length)
+	}
+
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value, nil
+	}
+
+	return string(runes[:length]), nil
+}
+
+func defaultValue(fallback string, value string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func replace(old string, replacement string, value string) string {
+	return strings.ReplaceAll(value, old, replacement)
+}
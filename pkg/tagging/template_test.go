@@ -0,0 +1,132 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var templateNode = &corev1.Node{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "ip-10-0-0-1.eu-west-1.compute.internal",
+		Labels: map[string]string{
+			"topology.kubernetes.io/zone":      "eu-west-1a",
+			"beta.kubernetes.io/instance-type": "m5.large",
+			"team":                             "",
+		},
+		Annotations: map[string]string{
+			"owner": "Platform",
+		},
+	},
+	Status: corev1.NodeStatus{
+		NodeInfo: corev1.NodeSystemInfo{
+			KubeletVersion: "v1.17.3",
+			OSImage:        "Amazon Linux 2",
+		},
+	},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		testName      string
+		value         string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			testName:      "plain value",
+			value:         "value",
+			expectedValue: "value",
+		},
+		{
+			testName:      "name",
+			value:         "{{ .Name }}",
+			expectedValue: "ip-10-0-0-1.eu-west-1.compute.internal",
+		},
+		{
+			testName:      "label",
+			value:         `zone-{{ .Labels "topology.kubernetes.io/zone" }}`,
+			expectedValue: "zone-eu-west-1a",
+		},
+		{
+			testName:      "annotation",
+			value:         `{{ .Annotations "owner" | lower }}`,
+			expectedValue: "platform",
+		},
+		{
+			testName:      "instance type from beta label",
+			value:         "{{ .InstanceType | upper }}",
+			expectedValue: "M5.LARGE",
+		},
+		{
+			testName:      "kubelet version and node info",
+			value:         "{{ .KubeletVersion }} {{ .NodeInfo.OSImage }}",
+			expectedValue: "v1.17.3 Amazon Linux 2",
+		},
+		{
+			testName:      "truncate",
+			value:         "{{ .Name | truncate 11 }}",
+			expectedValue: "ip-10-0-0-1",
+		},
+		{
+			testName:      "default",
+			value:         `{{ .Labels "team" | default "unknown" }}`,
+			expectedValue: "unknown",
+		},
+		{
+			testName:      "default for a missing label",
+			value:         `{{ .OptionalLabels "missing" | default "unknown" }}`,
+			expectedValue: "unknown",
+		},
+		{
+			testName:      "optional label",
+			value:         `{{ .OptionalLabels "topology.kubernetes.io/zone" | default "unknown" }}`,
+			expectedValue: "eu-west-1a",
+		},
+		{
+			testName:      "default for a missing annotation",
+			value:         `{{ .OptionalAnnotations "missing" | default "unknown" }}`,
+			expectedValue: "unknown",
+		},
+		{
+			testName:      "replace",
+			value:         `{{ .Labels "topology.kubernetes.io/zone" | replace "-" "_" }}`,
+			expectedValue: "eu_west_1a",
+		},
+		{
+			testName: "missing label",
+			value:    `{{ .Labels "missing" }}`,
+			expectedError: `failed to render template for tag "tag": template: tag:1:3: executing "tag" at <.Labels>: ` +
+				`error calling Labels: node ip-10-0-0-1.eu-west-1.compute.internal has no label "missing"`,
+		},
+		{
+			testName: "missing field",
+			value:    "{{ .Missing }}",
+			expectedError: `failed to render template for tag "tag": template: tag:1:3: executing "tag" at <.Missing>: ` +
+				`can't evaluate field Missing in type tagging.NodeData`,
+		},
+		{
+			testName:      "invalid template",
+			value:         "{{ .Name ",
+			expectedError: `invalid template for tag "tag": template: tag:1: unclosed action`,
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			rendered, err := Render(map[string]string{"tag": testData.value}, NewNodeData(templateNode))
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"tag": testData.expectedValue}, rendered)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tagging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ouzi-dev/node-tagger/pkg/constants"
+)
+
+const (
+	// MaxKeyLength is the maximum length of a tag key in characters
+	MaxKeyLength = 128
+	// MaxValueLength is the maximum length of a tag value in characters
+	MaxValueLength = 256
+	// MaxTagsPerResource is the maximum number of tags on a single resource
+	MaxTagsPerResource = 50
+
+	reservedPrefix = "aws:"
+)
+
+// ValidationError lists every problem found with a set of tags
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid tags: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the tags against the AWS tag limits before they reach the AWS APIs
+func Validate(tags map[string]string) error {
+	return validate(tags, false)
+}
+
+// ValidateTemplates checks tags whose values have not been rendered yet. The templates are parsed, but their length
+// can only be checked once they are rendered for a resource.
+func ValidateTemplates(tags map[string]string) error {
+	return validate(tags, true)
+}
+
+func validate(tags map[string]string, templates bool) error {
+	problems := []string{}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := tags[key]
+
+		switch {
+		case key == "":
+			problems = append(problems, "empty key")
+			continue
+		case utf8.RuneCountInString(key) > MaxKeyLength:
+			problems = append(problems, fmt.Sprintf("key %q is %d characters long, the maximum is %d",
+				key, utf8.RuneCountInString(key), MaxKeyLength))
+		case strings.HasPrefix(strings.ToLower(key), reservedPrefix):
+			problems = append(problems, fmt.Sprintf("key %q uses the reserved %s prefix", key, reservedPrefix))
+		case IsManagedKeysTag(constants.ManagedKeysTag, key) || IsManagedKeysTag(constants.VolumeManagedKeysTag, key):
+			problems = append(problems, fmt.Sprintf("key %q is reserved for node-tagger", key))
+		case strings.Contains(key, constants.ManagedKeysSeparator):
+			problems = append(problems, fmt.Sprintf("key %q contains a %q", key, constants.ManagedKeysSeparator))
+		}
+
+		if templates && strings.Contains(value, "{{") {
+			if _, err := parseTemplate(key, value); err != nil {
+				problems = append(problems, err.Error())
+			}
+
+			continue
+		}
+
+		if utf8.RuneCountInString(value) > MaxValueLength {
+			problems = append(problems, fmt.Sprintf("value of key %q is %d characters long, the maximum is %d",
+				key, utf8.RuneCountInString(value), MaxValueLength))
+		}
+	}
+
+	// node-tagger records the keys in as many tags as they need on top of the tags themselves
+	if managedKeysTags, err := FormatManagedKeys(constants.ManagedKeysTag, keys); err != nil {
+		problems = append(problems, err.Error())
+	} else if len(tags)+len(managedKeysTags) > MaxTagsPerResource {
+		problems = append(problems, fmt.Sprintf("%d tags requested, and node-tagger needs %d more to record their "+
+			"keys, the maximum is %d tags per resource", len(tags), len(managedKeysTags), MaxTagsPerResource))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
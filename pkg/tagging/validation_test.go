@@ -0,0 +1,99 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tooManyTags := map[string]string{}
+	for i := 0; i < MaxTagsPerResource; i++ {
+		tooManyTags[fmt.Sprintf("%d", i)] = "value"
+	}
+
+	tooManyLongKeys := map[string]string{}
+	for i := 0; i < 30; i++ {
+		tooManyLongKeys[fmt.Sprintf("%02d%s", i, strings.Repeat("a", 126))] = "value"
+	}
+
+	tests := []struct {
+		testName      string
+		tags          map[string]string
+		expectedError string
+	}{
+		{
+			testName: "valid tags",
+			tags: map[string]string{
+				"tag1":                   "value1",
+				strings.Repeat("a", 128): strings.Repeat("b", 256),
+			},
+		},
+		{
+			testName: "every problem is listed",
+			tags: map[string]string{
+				"":                                  "empty",
+				"AWS:tag":                           "value",
+				"node-tagger.ouzi.dev/managed-keys": "value",
+				"node-tagger.ouzi.dev/volume-managed-keys-2": "value",
+				"tag,1":                  "value",
+				strings.Repeat("a", 129): "value",
+				"tag2":                   strings.Repeat("b", 257),
+			},
+			expectedError: `invalid tags: empty key; key "AWS:tag" uses the reserved aws: prefix; ` +
+				`key "` + strings.Repeat("a", 129) + `" is 129 characters long, the maximum is 128; ` +
+				`key "node-tagger.ouzi.dev/managed-keys" is reserved for node-tagger; ` +
+				`key "node-tagger.ouzi.dev/volume-managed-keys-2" is reserved for node-tagger; key "tag,1" contains a ","; ` +
+				`value of key "tag2" is 257 characters long, the maximum is 256`,
+		},
+		{
+			testName: "too many tags",
+			tags:     tooManyTags,
+			expectedError: "invalid tags: 50 tags requested, and node-tagger needs 1 more to record their keys, " +
+				"the maximum is 50 tags per resource",
+		},
+		{
+			testName: "long keys recorded in several tags",
+			tags: map[string]string{
+				strings.Repeat("a", 128): "value",
+				strings.Repeat("b", 128): "value",
+			},
+		},
+		{
+			testName: "too many long keys to be recorded",
+			tags:     tooManyLongKeys,
+			expectedError: "invalid tags: 30 tags requested, and node-tagger needs 30 more to record their keys, " +
+				"the maximum is 50 tags per resource",
+		},
+	}
+
+	for _, testData := range tests {
+		// pin testData var in this scope
+		testData := testData
+		t.Run(testData.testName, func(t *testing.T) {
+			err := Validate(testData.tags)
+
+			if testData.expectedError != "" {
+				assert.EqualError(t, err, testData.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	tags := map[string]string{
+		"tag1": "{{ .Name | truncate 10 }}" + strings.Repeat("a", 256),
+		"tag2": "{{ .Name ",
+	}
+
+	err := ValidateTemplates(tags)
+	assert.EqualError(t, err, `invalid tags: invalid template for tag "tag2": template: tag2:1: unclosed action`)
+
+	err = Validate(tags)
+	assert.EqualError(t, err, `invalid tags: value of key "tag1" is 281 characters long, the maximum is 256`)
+}